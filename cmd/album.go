@@ -6,9 +6,14 @@
 package cmd
 
 import (
-	"encoding/json"
+	"fmt"
 	"os"
+	"os/signal"
+	"path/filepath"
 	"strconv"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/pterm/pterm"
 	ucli "github.com/urfave/cli/v2"
@@ -21,7 +26,7 @@ import (
 var albumCmd = &ucli.Command{
 	Name:        "album",
 	Description: "Manages albums",
-	Subcommands: commands(autoCreateAlbums, listAlbums),
+	Subcommands: commands(autoCreateAlbums, listAlbums, addAlbumAssets, removeAlbumAssets, mergeAlbums, splitAlbum, pruneAlbums, shareAlbum, unshareAlbum, albumMembers, smartAlbums),
 }
 
 var autoCreateAlbums = &ucli.Command{
@@ -36,32 +41,149 @@ var autoCreateAlbums = &ucli.Command{
 			Name:  "skip-levels",
 			Usage: "skip folder levels names of group creation from root path.",
 		},
+		&ucli.BoolFlag{
+			Name:  "remote",
+			Usage: "reads the folder tree from the server, the path must be the one seen by Immich",
+		},
 		&ucli.StringFlag{
 			Name:  "original-path",
 			Usage: "sets the original path where the photos is stored in Immich",
 		},
 		&ucli.StringSliceFlag{
+			Name:  "path-mapping",
+			Usage: "set a local=server path prefix mapping, the longest local prefix matching a folder is used",
+		},
+		&literalSliceFlag{&ucli.StringSliceFlag{
 			Name:  "exclude",
-			Usage: "exclude files matching pattern",
+			Usage: "exclude files matching pattern, prefix with ! to re-include them, i: to ignore case and re: for regexps",
+		}},
+		&ucli.BoolFlag{
+			Name:  "exclude-ignore-case",
+			Usage: "matches the exclude patterns and .imtignore files ignoring case",
+		},
+		&literalSliceFlag{&ucli.StringSliceFlag{
+			Name:  "include",
+			Usage: "only create albums for folders matching pattern, prefix with ! to leave them out",
+		}},
+		&ucli.IntFlag{
+			Name:  "min-assets",
+			Usage: "skip folders with fewer assets than the minimum",
+		},
+		&ucli.StringFlag{
+			Name:  "media-type",
+			Usage: "only add assets of the media type: image or video",
+		},
+		&ucli.StringSliceFlag{
+			Name:  "extension",
+			Usage: "only add assets with the file extension (e.g. jpg)",
+		},
+		&ucli.BoolFlag{
+			Name:  "follow-symlinks",
+			Usage: "descends into symbolic links to folders, skipping the folders already walked",
+		},
+		&ucli.BoolFlag{
+			Name:  "no-ignore-files",
+			Usage: "disables the .imtignore files found in the folders",
+		},
+		&ucli.BoolFlag{
+			Name:  "legacy-globs",
+			Usage: "exclude patterns where * matches path separators and ** is not allowed",
 		},
 		&ucli.StringSliceFlag{
 			Name:  "rename",
 			Usage: "set a key/value album to be renamed",
 		},
+		&literalSliceFlag{&ucli.StringSliceFlag{
+			Name:  "rename-rule",
+			Usage: "set a pattern=replacement regexp rule to rename albums, applied in order",
+		}},
+		&ucli.BoolFlag{
+			Name:  "explain",
+			Usage: "shows which rule produced each album name without creating albums",
+		},
+		&ucli.StringFlag{
+			Name:  "name-template",
+			Usage: "sets the album name template (Go text/template)",
+		},
+		&ucli.BoolFlag{
+			Name:  "parse-dates",
+			Usage: "detects date prefixes/suffixes in folder names and strips them from album names",
+		},
+		&ucli.StringSliceFlag{
+			Name:  "date-layout",
+			Usage: "sets the Go time layouts of dates in folder names (e.g. 2006-01-02)",
+		},
+		&ucli.StringFlag{
+			Name:  "date-description",
+			Usage: "sets the album description using the folder date in Go time layout (e.g. January 2006)",
+		},
+		&ucli.StringFlag{
+			Name:  "date-group",
+			Usage: "groups folders with the same name across years: merge or year",
+			Value: cli.DateGroupMerge,
+		},
+		&ucli.StringFlag{
+			Name:  "on-conflict",
+			Usage: "sets the policy for folders with the same album name: merge, suffix, parent-prefix or fail",
+			Value: cli.ConflictMerge,
+		},
+		&ucli.StringSliceFlag{
+			Name:  "normalize",
+			Usage: "normalizes album names when matching them: nfc, casefold or whitespace",
+		},
+		&ucli.StringSliceFlag{
+			Name:  "share",
+			Usage: "shares the created albums with a user, formatted as email[:editor|viewer]",
+		},
 		&ucli.StringFlag{
 			Name:  "from-config",
-			Usage: "load parameters from config file",
+			Usage: "load parameters from config file (json, yaml or toml)",
+		},
+		&ucli.StringSliceFlag{
+			Name:  "job",
+			Usage: "runs only the named job of the config file, all jobs are run by default",
+		},
+		&ucli.BoolFlag{
+			Name:  "watch",
+			Usage: "keeps the albums in sync, watching the folder tree for added or changed folders",
+		},
+		&ucli.DurationFlag{
+			Name:  "debounce",
+			Usage: "quiet time waited after a change before syncing in watch mode",
+			Value: cli.DefaultWatchDebounce,
+		},
+		&ucli.BoolFlag{
+			Name:  "poll",
+			Usage: "polls the folders instead of using file system events in watch mode",
+		},
+		&ucli.DurationFlag{
+			Name:  "poll-interval",
+			Usage: "interval for polling the folders and retrying failed syncs in watch mode",
+			Value: cli.DefaultWatchPollInterval,
 		},
 	},
 	Action: withClient(func(cc *ucli.Context, cl *client.Client) error {
 		cfg := cc.String("from-config")
 		if cfg != "" {
-			opts, err := loadAutoCreateConfigFile(cfg)
+			jobs, err := cli.LoadAutoCreateJobs(cfg)
 			if err != nil {
 				return err
 			}
 
-			return autoCreateAlbumsAction(cc, cl, opts)
+			jobs, err = cli.SelectAutoCreateJobs(jobs, cc.StringSlice("job"))
+			if err != nil {
+				return err
+			}
+
+			if len(jobs) == 1 {
+				return autoCreateAlbumsAction(cc, cl, jobs[0].Options)
+			}
+
+			return autoCreateJobsAction(cc, cl, jobs)
+		}
+
+		if cc.IsSet("job") {
+			return errors.New("jobs are only allowed with a config file")
 		}
 
 		if cc.Args().Len() != 1 {
@@ -73,13 +195,51 @@ var autoCreateAlbums = &ucli.Command{
 			return err
 		}
 
+		rules, err := renameRules(cc, "rename-rule")
+		if err != nil {
+			return err
+		}
+
+		mappings, err := pathMappings(cc, "path-mapping")
+		if err != nil {
+			return err
+		}
+
 		opts := &cli.AutoCreateAlbumsOptions{
-			Folder:       cc.Args().First(),
-			Recursive:    cc.Bool("recursive"),
-			SkipLevels:   cc.Int("skip-levels"),
-			OriginalPath: cc.String("original-path"),
-			Exclude:      cc.StringSlice("exclude"),
-			Albums:       albums,
+			Folder:            cc.Args().First(),
+			Recursive:         cc.Bool("recursive"),
+			SkipLevels:        cc.Int("skip-levels"),
+			OriginalPath:      cc.String("original-path"),
+			PathMappings:      mappings,
+			Exclude:           literalSliceValue(cc, "exclude"),
+			Include:           literalSliceValue(cc, "include"),
+			MinAssets:         cc.Int("min-assets"),
+			LegacyGlobs:       cc.Bool("legacy-globs"),
+			ExcludeIgnoreCase: cc.Bool("exclude-ignore-case"),
+			NoIgnoreFiles:     cc.Bool("no-ignore-files"),
+			FollowSymlinks:    cc.Bool("follow-symlinks"),
+			Albums:            albums,
+			NameTemplate:      cc.String("name-template"),
+			RenameRules:       rules,
+			OnConflict:        cc.String("on-conflict"),
+			Normalize:         cc.StringSlice("normalize"),
+			Remote:            cc.Bool("remote"),
+			Share:             cc.StringSlice("share"),
+		}
+
+		if cc.IsSet("media-type") || cc.IsSet("extension") {
+			opts.Media = &cli.MediaFilter{
+				Type:       cc.String("media-type"),
+				Extensions: cc.StringSlice("extension"),
+			}
+		}
+
+		if cc.Bool("parse-dates") {
+			opts.Dates = &cli.FolderDateOptions{
+				Layouts:     cc.StringSlice("date-layout"),
+				Description: cc.String("date-description"),
+				Group:       cc.String("date-group"),
+			}
 		}
 
 		return autoCreateAlbumsAction(cc, cl, opts)
@@ -87,30 +247,242 @@ var autoCreateAlbums = &ucli.Command{
 }
 
 func autoCreateAlbumsAction(cc *ucli.Context, cl *client.Client, opts *cli.AutoCreateAlbumsOptions) error {
+	if cc.Bool("explain") {
+		return explainAlbumNames(cc, cl, opts)
+	}
+
+	if cc.Bool("watch") {
+		return watchAlbumsAction(cc, cl, opts)
+	}
+
 	spin, err := spinner(cc.App.Writer, "creating albums...").Start()
 	if err != nil {
 		return err
 	}
 
-	if err := cli.AutoCreateAlbums(cc.Context, cl, opts); err != nil {
+	report, err := cli.AutoCreateAlbums(cc.Context, cl, opts)
+	if err != nil {
+		return err
+	}
+
+	if err := spin.Stop(); err != nil {
 		return err
 	}
 
-	return spin.Stop()
+	if err := renderCollisions(report.Collisions); err != nil {
+		return err
+	}
+
+	return renderSkipped(report.Skipped)
+}
+
+// watchAlbumsAction keeps the albums in sync until interrupted, rendering
+// the outcome of every sync.
+func watchAlbumsAction(cc *ucli.Context, cl *client.Client, opts *cli.AutoCreateAlbumsOptions) error {
+	ctx, stop := signal.NotifyContext(cc.Context, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	wopts := cli.WatchOptions{
+		Debounce:     cc.Duration("debounce"),
+		Poll:         cc.Bool("poll"),
+		PollInterval: cc.Duration("poll-interval"),
+	}
+
+	return cli.WatchAutoCreateAlbums(ctx, cl, opts, wopts, func(report *cli.AutoCreateAlbumsReport, err error) {
+		now := time.Now().Format(time.DateTime)
+
+		if err != nil {
+			_, _ = fmt.Fprintf(cc.App.Writer, "%s sync failed, retrying in %s: %v\n", now, wopts.PollInterval, err)
+			return
+		}
+
+		_, _ = fmt.Fprintf(
+			cc.App.Writer,
+			"%s synced %d folders: %d albums created, %d assets added\n",
+			now,
+			len(report.Folders),
+			len(report.Created),
+			report.Assets,
+		)
+
+		_ = renderCollisions(report.Collisions)
+		_ = renderSkipped(report.Skipped)
+	})
+}
+
+// renderSkipped renders the folders skipped for having too few assets.
+func renderSkipped(skipped []cli.SkippedFolder) error {
+	if len(skipped) == 0 {
+		return nil
+	}
+
+	data := pterm.TableData{
+		{"SKIPPED FOLDER", "ASSETS"},
+	}
+
+	for _, s := range skipped {
+		data = append(data, []string{s.Folder, strconv.Itoa(s.Assets)})
+	}
+
+	return pterm.DefaultTable.
+		WithHasHeader().
+		WithData(data).
+		Render()
 }
 
-func loadAutoCreateConfigFile(name string) (*cli.AutoCreateAlbumsOptions, error) {
-	if name == "" {
-		return nil, errors.New("empty filename is not allowed")
+// autoCreateJobsAction runs the config jobs sequentially, rendering a combined
+// report at the end.
+func autoCreateJobsAction(cc *ucli.Context, cl *client.Client, jobs []cli.AutoCreateJob) error {
+	if cc.Bool("watch") {
+		return errors.New("watch mode supports a single job, use --job to select it")
+	}
+
+	if cc.Bool("explain") {
+		for _, job := range jobs {
+			pterm.DefaultSection.Println(job.Name)
+
+			if err := explainAlbumNames(cc, cl, job.Options); err != nil {
+				return err
+			}
+		}
+
+		return nil
 	}
 
-	content, err := os.ReadFile(name)
+	spin, err := spinner(cc.App.Writer, "running auto create jobs...").Start()
 	if err != nil {
-		return nil, errors.Errorf("unable to read auto create albums config file: %w", err)
+		return err
+	}
+
+	reports, jobsErr := cli.RunAutoCreateJobs(cc.Context, cl, jobs)
+
+	if err := spin.Stop(); err != nil {
+		return err
+	}
+
+	data := pterm.TableData{
+		{"JOB", "ALBUMS CREATED", "ASSETS ADDED", "COLLISIONS", "SKIPPED FOLDERS", "STATUS"},
 	}
 
-	var opts cli.AutoCreateAlbumsOptions
-	return &opts, json.Unmarshal(content, &opts)
+	var (
+		collisions []cli.AlbumCollision
+		skipped    []cli.SkippedFolder
+	)
+
+	for _, r := range reports {
+		status := "ok"
+		if r.Err != nil {
+			status = r.Err.Error()
+		}
+
+		data = append(data, []string{
+			r.Job,
+			strconv.Itoa(len(r.Report.Created)),
+			strconv.Itoa(r.Report.Assets),
+			strconv.Itoa(len(r.Report.Collisions)),
+			strconv.Itoa(len(r.Report.Skipped)),
+			status,
+		})
+
+		collisions = append(collisions, r.Report.Collisions...)
+		skipped = append(skipped, r.Report.Skipped...)
+	}
+
+	if err := pterm.DefaultTable.WithHasHeader().WithData(data).Render(); err != nil {
+		return err
+	}
+
+	if err := renderCollisions(collisions); err != nil {
+		return err
+	}
+
+	if err := renderSkipped(skipped); err != nil {
+		return err
+	}
+
+	return jobsErr
+}
+
+// renderCollisions renders the album name collisions resolved.
+func renderCollisions(collisions []cli.AlbumCollision) error {
+	if len(collisions) == 0 {
+		return nil
+	}
+
+	data := pterm.TableData{
+		{"ALBUM", "CONFLICT", "RESOLUTION"},
+	}
+
+	for _, c := range collisions {
+		data = append(data, []string{c.Album, c.Conflict, c.Resolution})
+	}
+
+	return pterm.DefaultTable.
+		WithHasHeader().
+		WithData(data).
+		Render()
+}
+
+// explainAlbumNames renders how the album name of every folder was resolved.
+func explainAlbumNames(cc *ucli.Context, cl *client.Client, opts *cli.AutoCreateAlbumsOptions) error {
+	explanations, err := cli.ExplainAlbumNames(cc.Context, cl, opts)
+	if err != nil {
+		return err
+	}
+
+	data := pterm.TableData{
+		{"FOLDER", "ALBUM", "RESOLVED BY"},
+	}
+
+	for _, e := range explanations {
+		steps := "folder name"
+		if len(e.Steps) > 0 {
+			steps = strings.Join(e.Steps, ", ")
+		}
+
+		data = append(data, []string{e.Folder, e.Album, steps})
+	}
+
+	return pterm.DefaultTable.
+		WithHasHeader().
+		WithData(data).
+		Render()
+}
+
+// renameRules reads flag string slice as an ordered list of rename rules.
+func renameRules(cc *ucli.Context, name string) ([]cli.RenameRule, error) {
+	items := literalSliceValue(cc, name)
+
+	rules := make([]cli.RenameRule, 0, len(items))
+
+	for _, item := range items {
+		pattern, replacement, ok := strings.Cut(item, "=")
+		if !ok {
+			return rules, errors.Errorf("%s '%s' must be formatted as pattern=replacement", name, item)
+		}
+
+		rules = append(rules, cli.RenameRule{Pattern: pattern, Replacement: replacement})
+	}
+
+	return rules, nil
+}
+
+// pathMappings reads flag string slice as a list of path mappings.
+func pathMappings(cc *ucli.Context, name string) ([]cli.PathMapping, error) {
+	items := cc.StringSlice(name)
+
+	mappings := make([]cli.PathMapping, 0, len(items))
+
+	for _, item := range items {
+		local, server, ok := strings.Cut(item, "=")
+		if !ok {
+			return mappings, errors.Errorf("%s '%s' must be formatted as local=server", name, item)
+		}
+
+		mappings = append(mappings, cli.PathMapping{Local: local, Server: server})
+	}
+
+	return mappings, nil
 }
 
 var listAlbums = &ucli.Command{
@@ -136,3 +508,487 @@ var listAlbums = &ucli.Command{
 			Render()
 	}),
 }
+
+var addAlbumAssets = &ucli.Command{
+	Name:        "add",
+	Description: "adds a list of assets into an album, creating the album when missing",
+	ArgsUsage:   "<album>",
+	Flags: []ucli.Flag{
+		&ucli.StringFlag{
+			Name:  "from-file",
+			Usage: "file listing asset IDs, original paths or local paths, one per line (- for stdin)",
+		},
+		&ucli.StringFlag{
+			Name:  "original-path",
+			Usage: "sets the original path in Immich of the relative paths",
+		},
+		&ucli.StringSliceFlag{
+			Name:  "path-mapping",
+			Usage: "set a local=server path prefix mapping, the longest local prefix matching a path is used",
+		},
+	},
+	Action: withClient(func(cc *ucli.Context, cl *client.Client) error {
+		if cc.Args().Len() != 1 {
+			return errors.New("Empty album name is not allowed")
+		}
+
+		if !cc.IsSet("from-file") {
+			return errors.New(`Required flag "from-file" not set`)
+		}
+
+		mappings, err := pathMappings(cc, "path-mapping")
+		if err != nil {
+			return err
+		}
+
+		entries, err := albumEntries(cc, cc.String("from-file"))
+		if err != nil {
+			return err
+		}
+
+		opts := &cli.AddAlbumAssetsOptions{
+			Album:        cc.Args().First(),
+			Entries:      entries,
+			OriginalPath: cc.String("original-path"),
+			PathMappings: mappings,
+		}
+
+		spin, err := spinner(cc.App.Writer, "adding assets...").Start()
+		if err != nil {
+			return err
+		}
+
+		report, addErr := cli.AddAlbumAssets(cc.Context, cl, opts)
+
+		if err := spin.Stop(); err != nil {
+			return err
+		}
+
+		if addErr != nil {
+			return addErr
+		}
+
+		created := "no"
+		if report.Created {
+			created = "yes"
+		}
+
+		data := pterm.TableData{
+			{"ALBUM", "CREATED", "ASSETS ADDED", "UNRESOLVED ENTRIES"},
+			{opts.Album, created, strconv.Itoa(report.Assets), strconv.Itoa(len(report.Unresolved))},
+		}
+
+		if err := pterm.DefaultTable.WithHasHeader().WithData(data).Render(); err != nil {
+			return err
+		}
+
+		if len(report.Unresolved) == 0 {
+			return nil
+		}
+
+		data = pterm.TableData{
+			{"UNRESOLVED ENTRY"},
+		}
+
+		for _, entry := range report.Unresolved {
+			data = append(data, []string{entry})
+		}
+
+		if err := pterm.DefaultTable.WithHasHeader().WithData(data).Render(); err != nil {
+			return err
+		}
+
+		return errors.Errorf("%d entries could not be resolved", len(report.Unresolved))
+	}),
+}
+
+// albumEntries reads the entries of the file, or of stdin for "-".
+func albumEntries(cc *ucli.Context, name string) ([]string, error) {
+	if name == "-" {
+		return cli.ReadAlbumEntries(cc.App.Reader)
+	}
+
+	f, err := os.Open(filepath.Clean(name))
+	if err != nil {
+		return nil, fmt.Errorf("unable to open entries file: %w", err)
+	}
+
+	defer func() { _ = f.Close() }()
+
+	return cli.ReadAlbumEntries(f)
+}
+
+var removeAlbumAssets = &ucli.Command{
+	Name:        "remove",
+	Description: "removes the album assets matching the paths, globs or IDs",
+	ArgsUsage:   "<album>",
+	Flags: []ucli.Flag{
+		&ucli.StringSliceFlag{
+			Name:  "path",
+			Usage: "original path of a file or folder, as seen by Immich",
+		},
+		&ucli.StringSliceFlag{
+			Name:  "glob",
+			Usage: "original path glob pattern (same syntax as exclude)",
+		},
+		&ucli.StringSliceFlag{
+			Name:  "id",
+			Usage: "asset ID",
+		},
+		&ucli.BoolFlag{
+			Name:  "dry-run",
+			Usage: "show the assets to be removed, without removing them",
+		},
+	},
+	Action: withClient(func(cc *ucli.Context, cl *client.Client) error {
+		if cc.Args().Len() != 1 {
+			return errors.New("Empty album name is not allowed")
+		}
+
+		opts := &cli.RemoveAlbumAssetsOptions{
+			Album:  cc.Args().First(),
+			Paths:  cc.StringSlice("path"),
+			Globs:  cc.StringSlice("glob"),
+			IDs:    cc.StringSlice("id"),
+			DryRun: cc.Bool("dry-run"),
+		}
+
+		spin, err := spinner(cc.App.Writer, "removing assets...").Start()
+		if err != nil {
+			return err
+		}
+
+		assets, removeErr := cli.RemoveAlbumAssets(cc.Context, cl, opts)
+
+		if err := spin.Stop(); err != nil {
+			return err
+		}
+
+		if removeErr != nil {
+			return removeErr
+		}
+
+		data := pterm.TableData{
+			{"ID", "ORIGINAL PATH"},
+		}
+
+		for _, a := range assets {
+			data = append(data, []string{a.ID, a.OriginalPath})
+		}
+
+		if err := pterm.DefaultTable.WithHasHeader().WithData(data).Render(); err != nil {
+			return err
+		}
+
+		if opts.DryRun {
+			_, err = fmt.Fprintf(cc.App.Writer, "dry run, %d assets to be removed\n", len(assets))
+			return err
+		}
+
+		_, err = fmt.Fprintf(cc.App.Writer, "%d assets removed\n", len(assets))
+
+		return err
+	}),
+}
+
+var mergeAlbums = &ucli.Command{
+	Name:        "merge",
+	Description: "moves the assets of the source albums into the target album and deletes the sources",
+	ArgsUsage:   "<target> <source>...",
+	Flags: []ucli.Flag{
+		&ucli.BoolFlag{
+			Name:  "merge-descriptions",
+			Usage: "append the source descriptions to the target description",
+		},
+		&ucli.BoolFlag{
+			Name:  "merge-users",
+			Usage: "share the target with the users of the sources",
+		},
+	},
+	Action: withClient(func(cc *ucli.Context, cl *client.Client) error {
+		if cc.Args().Len() < 2 {
+			return errors.New("target and source albums are required")
+		}
+
+		opts := &cli.MergeAlbumsOptions{
+			Target:            cc.Args().First(),
+			Sources:           cc.Args().Tail(),
+			MergeDescriptions: cc.Bool("merge-descriptions"),
+			MergeUsers:        cc.Bool("merge-users"),
+		}
+
+		spin, err := spinner(cc.App.Writer, "merging albums...").Start()
+		if err != nil {
+			return err
+		}
+
+		report, mergeErr := cli.MergeAlbums(cc.Context, cl, opts)
+
+		if err := spin.Stop(); err != nil {
+			return err
+		}
+
+		if mergeErr != nil {
+			return mergeErr
+		}
+
+		data := pterm.TableData{
+			{"ALBUM", "ASSETS MOVED", "USERS ADDED", "ALBUMS DELETED"},
+			{opts.Target, strconv.Itoa(report.Assets), strconv.Itoa(report.Users), strings.Join(report.Deleted, ", ")},
+		}
+
+		return pterm.DefaultTable.
+			WithHasHeader().
+			WithData(data).
+			Render()
+	}),
+}
+
+var splitAlbum = &ucli.Command{
+	Name:        "split",
+	Description: "creates child albums from the assets of an album, grouped by year, month or folder",
+	ArgsUsage:   "<album>",
+	Flags: []ucli.Flag{
+		&ucli.StringFlag{
+			Name:  "by",
+			Usage: "group the assets by: year, month or folder",
+			Value: cli.SplitByMonth,
+		},
+	},
+	Action: withClient(func(cc *ucli.Context, cl *client.Client) error {
+		if cc.Args().Len() != 1 {
+			return errors.New("Empty album name is not allowed")
+		}
+
+		spin, err := spinner(cc.App.Writer, "splitting album...").Start()
+		if err != nil {
+			return err
+		}
+
+		report, splitErr := cli.SplitAlbum(cc.Context, cl, cc.Args().First(), cc.String("by"))
+
+		if err := spin.Stop(); err != nil {
+			return err
+		}
+
+		if splitErr != nil {
+			return splitErr
+		}
+
+		data := pterm.TableData{
+			{"ALBUM", "CREATED", "ASSETS ADDED"},
+		}
+
+		for _, a := range report.Albums {
+			created := "no"
+			if a.Created {
+				created = "yes"
+			}
+
+			data = append(data, []string{a.Name, created, strconv.Itoa(a.Assets)})
+		}
+
+		if err := pterm.DefaultTable.WithHasHeader().WithData(data).Render(); err != nil {
+			return err
+		}
+
+		if report.Skipped == 0 {
+			return nil
+		}
+
+		_, err = fmt.Fprintf(cc.App.Writer, "%d assets without a date skipped\n", report.Skipped)
+
+		return err
+	}),
+}
+
+var pruneAlbums = &ucli.Command{
+	Name:        "prune",
+	Description: "finds and deletes empty albums, albums with trashed assets only and duplicate albums",
+	Flags: []ucli.Flag{
+		&ucli.StringSliceFlag{
+			Name:  "kind",
+			Usage: "kinds of albums to find: empty, trashed, duplicate-assets or duplicate-name (default: all)",
+		},
+		&ucli.StringSliceFlag{
+			Name:  "normalize",
+			Usage: "normalize album names when finding duplicate names: nfc, casefold or whitespace",
+		},
+		&ucli.BoolFlag{
+			Name:    "yes",
+			Aliases: []string{"y"},
+			Usage:   "delete all the albums found, without selecting them",
+		},
+		&ucli.BoolFlag{
+			Name:  "dry-run",
+			Usage: "only list the albums found",
+		},
+	},
+	Action: withClient(func(cc *ucli.Context, cl *client.Client) error {
+		opts := &cli.PruneOptions{
+			Kinds:     cc.StringSlice("kind"),
+			Normalize: cc.StringSlice("normalize"),
+		}
+
+		spin, err := spinner(cc.App.Writer, "finding albums...").Start()
+		if err != nil {
+			return err
+		}
+
+		candidates, findErr := cli.FindPruneCandidates(cc.Context, cl, opts)
+
+		if err := spin.Stop(); err != nil {
+			return err
+		}
+
+		if findErr != nil {
+			return findErr
+		}
+
+		if len(candidates) == 0 {
+			_, err := fmt.Fprintln(cc.App.Writer, "no albums to prune")
+			return err
+		}
+
+		data := pterm.TableData{
+			{"ID", "NAME", "NUMBER OF ASSETS", "REASON"},
+		}
+
+		options := make([]string, 0, len(candidates))
+		albums := make(map[string]cli.Album, len(candidates))
+
+		for _, c := range candidates {
+			reason := c.Kind
+			if c.Duplicate != "" {
+				reason = fmt.Sprintf("%s of %q", c.Kind, c.Duplicate)
+			}
+
+			data = append(data, []string{c.Album.ID, c.Album.Name, strconv.FormatInt(c.Album.AssetCount, 10), reason})
+
+			option := fmt.Sprintf("%s (%s, %s)", c.Album.Name, reason, c.Album.ID)
+			options = append(options, option)
+			albums[option] = c.Album
+		}
+
+		if err := pterm.DefaultTable.WithHasHeader().WithData(data).Render(); err != nil {
+			return err
+		}
+
+		if cc.Bool("dry-run") {
+			return nil
+		}
+
+		selected := options
+
+		if !cc.Bool("yes") {
+			selected, err = pterm.DefaultInteractiveMultiselect.
+				WithOptions(options).
+				WithFilter(false).
+				Show("Select the albums to delete")
+			if err != nil {
+				return err
+			}
+		}
+
+		deleted := make(cli.Albums, 0, len(selected))
+		for _, option := range selected {
+			deleted = append(deleted, albums[option])
+		}
+
+		if err := cli.DeleteAlbums(cc.Context, cl, deleted); err != nil {
+			return err
+		}
+
+		_, err = fmt.Fprintf(cc.App.Writer, "%d albums deleted\n", len(deleted))
+
+		return err
+	}),
+}
+
+var shareAlbum = &ucli.Command{
+	Name:        "share",
+	Description: "shares an album with users, updating the role of the users already shared with",
+	ArgsUsage:   "<album>",
+	Flags: []ucli.Flag{
+		&ucli.StringSliceFlag{
+			Name:  "user",
+			Usage: "user to share with, formatted as email[:editor|viewer], the role defaults to viewer",
+		},
+	},
+	Action: withClient(func(cc *ucli.Context, cl *client.Client) error {
+		if cc.Args().Len() != 1 {
+			return errors.New("Empty album name is not allowed")
+		}
+
+		report, err := cli.ShareAlbum(cc.Context, cl, cc.Args().First(), cc.StringSlice("user"))
+		if err != nil {
+			return err
+		}
+
+		data := pterm.TableData{
+			{"ALBUM", "USERS ADDED", "USERS UPDATED"},
+			{cc.Args().First(), strconv.Itoa(report.Added), strconv.Itoa(report.Updated)},
+		}
+
+		return pterm.DefaultTable.
+			WithHasHeader().
+			WithData(data).
+			Render()
+	}),
+}
+
+var unshareAlbum = &ucli.Command{
+	Name:        "unshare",
+	Description: "stops sharing an album with users",
+	ArgsUsage:   "<album>",
+	Flags: []ucli.Flag{
+		&ucli.StringSliceFlag{
+			Name:  "user",
+			Usage: "email of the user to stop sharing with",
+		},
+	},
+	Action: withClient(func(cc *ucli.Context, cl *client.Client) error {
+		if cc.Args().Len() != 1 {
+			return errors.New("Empty album name is not allowed")
+		}
+
+		users := cc.StringSlice("user")
+
+		if err := cli.UnshareAlbum(cc.Context, cl, cc.Args().First(), users); err != nil {
+			return err
+		}
+
+		_, err := fmt.Fprintf(cc.App.Writer, "%d users removed\n", len(users))
+
+		return err
+	}),
+}
+
+var albumMembers = &ucli.Command{
+	Name:        "members",
+	Description: "lists the owner and the users an album is shared with",
+	ArgsUsage:   "<album>",
+	Action: withClient(func(cc *ucli.Context, cl *client.Client) error {
+		if cc.Args().Len() != 1 {
+			return errors.New("Empty album name is not allowed")
+		}
+
+		members, err := cli.AlbumMembers(cc.Context, cl, cc.Args().First())
+		if err != nil {
+			return err
+		}
+
+		data := pterm.TableData{
+			{"NAME", "EMAIL", "ROLE"},
+		}
+
+		for _, m := range members {
+			data = append(data, []string{m.Name, m.Email, m.Role})
+		}
+
+		return pterm.DefaultTable.
+			WithHasHeader().
+			WithData(data).
+			Render()
+	}),
+}
@@ -0,0 +1,70 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package cmd
+
+import (
+	"io"
+	"reflect"
+	"testing"
+
+	ucli "github.com/urfave/cli/v2"
+
+	"github.com/faabiosr/imt/internal/cli"
+)
+
+// runFlags parses the args with the flags of the command, running the action.
+func runFlags(t *testing.T, cmd *ucli.Command, args []string, action ucli.ActionFunc) {
+	t.Helper()
+
+	app := &ucli.App{
+		Name:      "imt",
+		Writer:    io.Discard,
+		ErrWriter: io.Discard,
+		Commands:  []*ucli.Command{{Name: cmd.Name, Flags: cmd.Flags, Action: action}},
+	}
+
+	if err := app.Run(append([]string{"imt", cmd.Name}, args...)); err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+}
+
+func TestAlbum_renameRules(t *testing.T) {
+	var rules []cli.RenameRule
+
+	args := []string{"--rename-rule", "_= ", "--rename-rule", `^\d{2,4} - =`}
+
+	runFlags(t, autoCreateAlbums, args, func(cc *ucli.Context) error {
+		var err error
+		rules, err = renameRules(cc, "rename-rule")
+
+		return err
+	})
+
+	expected := []cli.RenameRule{{Pattern: "_", Replacement: " "}, {Pattern: `^\d{2,4} - `, Replacement: ""}}
+
+	if !reflect.DeepEqual(rules, expected) {
+		t.Errorf("unexpected rules: %v (expected %v)", rules, expected)
+	}
+}
+
+func TestAlbum_excludeFlag(t *testing.T) {
+	var exclude, include []string
+
+	args := []string{"--exclude", `re:^\d{2,4}$`, "--exclude", "{{\\d{2,4}}}", "--include", "2023, 2024"}
+
+	runFlags(t, autoCreateAlbums, args, func(cc *ucli.Context) error {
+		exclude, include = literalSliceValue(cc, "exclude"), literalSliceValue(cc, "include")
+		return nil
+	})
+
+	if expected := []string{`re:^\d{2,4}$`, `{{\d{2,4}}}`}; !reflect.DeepEqual(exclude, expected) {
+		t.Errorf("unexpected exclude patterns: %v (expected %v)", exclude, expected)
+	}
+
+	if expected := []string{"2023, 2024"}; !reflect.DeepEqual(include, expected) {
+		t.Errorf("unexpected include patterns: %v (expected %v)", include, expected)
+	}
+}
@@ -0,0 +1,196 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/pterm/pterm"
+	ucli "github.com/urfave/cli/v2"
+
+	"github.com/faabiosr/imt/internal/cli"
+	"github.com/faabiosr/imt/internal/client"
+	"github.com/faabiosr/imt/internal/errors"
+)
+
+var assetCmd = &ucli.Command{
+	Name:        "asset",
+	Description: "Manages assets",
+	Subcommands: commands(searchAssets),
+}
+
+// searchFlags are the flags of the asset search filters.
+var searchFlags = []ucli.Flag{
+	&ucli.StringFlag{
+		Name:    "query",
+		Aliases: []string{"q"},
+		Usage:   "smart search query, matching the assets by their content",
+	},
+	&ucli.StringFlag{
+		Name:  "taken-after",
+		Usage: "date (2006-01-02) or RFC 3339 timestamp the assets were taken after",
+	},
+	&ucli.StringFlag{
+		Name:  "taken-before",
+		Usage: "date (2006-01-02) or RFC 3339 timestamp the assets were taken before",
+	},
+	&ucli.StringFlag{
+		Name:  "make",
+		Usage: "camera make",
+	},
+	&ucli.StringFlag{
+		Name:  "model",
+		Usage: "camera model",
+	},
+	&ucli.StringFlag{
+		Name:  "city",
+		Usage: "city where the assets were taken",
+	},
+	&ucli.StringFlag{
+		Name:  "country",
+		Usage: "country where the assets were taken",
+	},
+	&ucli.StringFlag{
+		Name:  "type",
+		Usage: "asset type: image or video",
+	},
+	&ucli.BoolFlag{
+		Name:  "favorite",
+		Usage: "only favorite assets, or only the other ones with --favorite=false",
+	},
+	&ucli.StringFlag{
+		Name:  "path",
+		Usage: "original path prefix, as seen by Immich",
+	},
+	&ucli.StringFlag{
+		Name:  "filename",
+		Usage: "part of the original filename",
+	},
+	&ucli.IntFlag{
+		Name:  "limit",
+		Usage: "maximum number of assets fetched, smart searches fetch 250 by default",
+	},
+}
+
+var searchAssets = &ucli.Command{
+	Name:        "search",
+	Description: "searches assets by metadata or by content with a smart search query",
+	Flags: append(searchFlags, &ucli.StringFlag{
+		Name:    "output",
+		Aliases: []string{"o"},
+		Usage:   "output format: table, json or ids",
+		Value:   "table",
+	}),
+	Action: withClient(func(cc *ucli.Context, cl *client.Client) error {
+		search, err := assetSearch(cc)
+		if err != nil {
+			return err
+		}
+
+		output := cc.String("output")
+		if output != "table" && output != "json" && output != "ids" {
+			return errors.Errorf("invalid output format %q, must be table, json or ids", output)
+		}
+
+		assets, err := cli.SearchAssets(cc.Context, cl, search)
+		if err != nil {
+			return err
+		}
+
+		switch output {
+		case "json":
+			enc := json.NewEncoder(cc.App.Writer)
+			enc.SetIndent("", "  ")
+
+			return enc.Encode(assets)
+		case "ids":
+			for _, a := range assets {
+				if _, err := fmt.Fprintln(cc.App.Writer, a.ID); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		}
+
+		data := pterm.TableData{
+			{"ID", "TYPE", "TAKEN", "FAVORITE", "ORIGINAL PATH"},
+		}
+
+		for _, a := range assets {
+			taken := ""
+			if !a.LocalDateTime.IsZero() {
+				taken = a.LocalDateTime.Format(time.DateTime)
+			}
+
+			favorite := ""
+			if a.IsFavorite {
+				favorite = "yes"
+			}
+
+			data = append(data, []string{a.ID, a.Type, taken, favorite, a.OriginalPath})
+		}
+
+		return pterm.DefaultTable.
+			WithHasHeader().
+			WithData(data).
+			Render()
+	}),
+}
+
+// assetSearch reads the search filter flags.
+func assetSearch(cc *ucli.Context) (*cli.AssetSearch, error) {
+	s := &cli.AssetSearch{
+		Query:      cc.String("query"),
+		Make:       cc.String("make"),
+		Model:      cc.String("model"),
+		City:       cc.String("city"),
+		Country:    cc.String("country"),
+		Type:       cc.String("type"),
+		PathPrefix: cc.String("path"),
+		FileName:   cc.String("filename"),
+		Limit:      cc.Int("limit"),
+	}
+
+	if cc.IsSet("favorite") {
+		favorite := cc.Bool("favorite")
+		s.Favorite = &favorite
+	}
+
+	var err error
+
+	if s.TakenAfter, err = searchTime(cc, "taken-after"); err != nil {
+		return nil, err
+	}
+
+	if s.TakenBefore, err = searchTime(cc, "taken-before"); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// searchTime reads the flag as a date in the local time zone or as a RFC 3339
+// timestamp.
+func searchTime(cc *ucli.Context, name string) (time.Time, error) {
+	value := cc.String(name)
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	if t, err := time.ParseInLocation(time.DateOnly, value, time.Local); err == nil {
+		return t, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return t, errors.Errorf("%s '%s' must be a date (2006-01-02) or a RFC 3339 timestamp", name, value)
+	}
+
+	return t, nil
+}
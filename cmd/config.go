@@ -0,0 +1,312 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/pterm/pterm"
+	ucli "github.com/urfave/cli/v2"
+
+	"github.com/faabiosr/imt/internal/cli"
+	"github.com/faabiosr/imt/internal/errors"
+)
+
+var configCmd = &ucli.Command{
+	Name:        "config",
+	Description: "Manages config files and user settings",
+	Subcommands: commands(validateConfig, configSchema, getSetting, setSetting, listSettings),
+}
+
+var validateConfig = &ucli.Command{
+	Name:        "validate",
+	Description: "validates an auto create albums config file without any server calls",
+	ArgsUsage:   "<file>",
+	Action: func(cc *ucli.Context) error {
+		if cc.Args().Len() != 1 {
+			return errors.New("Empty path is not allowed")
+		}
+
+		issues, err := cli.ValidateAutoCreateConfig(cc.Args().First())
+		if err != nil {
+			return err
+		}
+
+		if len(issues) == 0 {
+			_, err := fmt.Fprintln(cc.App.Writer, "config file is valid")
+			return err
+		}
+
+		data := pterm.TableData{
+			{"LINE", "COLUMN", "PATH", "ERROR"},
+		}
+
+		for _, i := range issues {
+			data = append(data, []string{strconv.Itoa(i.Line), strconv.Itoa(i.Column), i.Path, i.Message})
+		}
+
+		if err := pterm.DefaultTable.WithHasHeader().WithData(data).Render(); err != nil {
+			return err
+		}
+
+		return errors.Errorf("config file has %d errors", len(issues))
+	},
+}
+
+var configSchema = &ucli.Command{
+	Name:        "schema",
+	Description: "prints the JSON Schema of the auto create albums config files",
+	Action: func(cc *ucli.Context) error {
+		schema, err := cli.AutoCreateConfigSchema()
+		if err != nil {
+			return err
+		}
+
+		_, err = fmt.Fprintln(cc.App.Writer, string(schema))
+
+		return err
+	},
+}
+
+var getSetting = &ucli.Command{
+	Name:        "get",
+	Description: "prints the value of a user setting",
+	ArgsUsage:   "<key>",
+	Action: func(cc *ucli.Context) error {
+		if cc.Args().Len() != 1 {
+			return errors.New("Empty key is not allowed")
+		}
+
+		key := cc.Args().First()
+		if err := checkSettingKey(cc.App, key); err != nil {
+			return err
+		}
+
+		v, ok := userSettings(cc).Get(key)
+		if !ok {
+			return errors.Errorf("setting %q is not set", key)
+		}
+
+		_, err := fmt.Fprintln(cc.App.Writer, strings.Join(v.Values, "\n"))
+
+		return err
+	},
+}
+
+var setSetting = &ucli.Command{
+	Name:        "set",
+	Description: "stores the value of a user setting, keys are flag names optionally prefixed by the command path, like exclude or album.auto-create.exclude",
+	ArgsUsage:   "<key> <value> [value...]",
+	Action: func(cc *ucli.Context) error {
+		if cc.Args().Len() < 2 {
+			return errors.New("Empty key or value is not allowed")
+		}
+
+		key := cc.Args().First()
+		if err := checkSettingValues(cc.App, key, cc.Args().Tail()); err != nil {
+			return err
+		}
+
+		settings := userSettings(cc)
+		if err := settings.Set(key, cc.Args().Tail()); err != nil {
+			return err
+		}
+
+		return settings.Save()
+	},
+}
+
+var listSettings = &ucli.Command{
+	Name:        "list",
+	Description: "lists the user settings and environment variables overriding them",
+	Action: func(cc *ucli.Context) error {
+		data := pterm.TableData{
+			{"KEY", "VALUE", "SOURCE"},
+		}
+
+		for _, v := range userSettings(cc).List(settingKeys(cc.App)) {
+			source := v.Source
+			if source == cli.SettingSourceEnv {
+				source = cli.SettingEnv(v.Key)
+			}
+
+			data = append(data, []string{v.Key, strings.Join(v.Values, ", "), source})
+		}
+
+		return pterm.DefaultTable.WithHasHeader().WithData(data).Render()
+	},
+}
+
+const settingsKey = "settings"
+
+// userSettings returns the user settings loaded by the app.
+func userSettings(cc *ucli.Context) *cli.Settings {
+	return cc.App.Metadata[settingsKey].(*cli.Settings)
+}
+
+// withSettings wraps the command before func, filling the flags not set with
+// the user settings.
+func withSettings(before ucli.BeforeFunc) ucli.BeforeFunc {
+	return func(cc *ucli.Context) error {
+		if err := applySettings(cc, cc.Command.Flags, strings.Fields(cc.Command.HelpName)[1:]); err != nil {
+			return err
+		}
+
+		if before != nil {
+			return before(cc)
+		}
+
+		return nil
+	}
+}
+
+// applySettings sets the flags not set by the command line, following the
+// precedence: flag, environment variable, settings file and default value.
+func applySettings(cc *ucli.Context, flags []ucli.Flag, command []string) error {
+	settings, ok := cc.App.Metadata[settingsKey].(*cli.Settings)
+	if !ok {
+		return nil
+	}
+
+	for _, f := range flags {
+		name := f.Names()[0]
+		if name == ucli.HelpFlag.Names()[0] || cc.IsSet(name) {
+			continue
+		}
+
+		lookup := settings.Lookup
+		if len(command) > 0 && appFlag(cc.App, name) {
+			lookup = commandSetting(settings)
+		}
+
+		v, ok := lookup(command, name)
+		if !ok {
+			continue
+		}
+
+		for _, value := range v.Values {
+			if err := cc.Set(name, value); err != nil {
+				source := v.Key
+				if v.Source == cli.SettingSourceEnv {
+					source = cli.SettingEnv(v.Key)
+				}
+
+				return errors.Errorf("invalid setting %s: %w", source, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// appFlag checks if the name is a flag of the app, set before any command.
+func appFlag(app *ucli.App, name string) bool {
+	return slices.ContainsFunc(app.Flags, func(f ucli.Flag) bool { return f.Names()[0] == name })
+}
+
+// commandSetting returns a lookup of the settings of the command only, for the
+// command flags named like an app flag, like daemon --config.
+func commandSetting(settings *cli.Settings) func(command []string, name string) (cli.Setting, bool) {
+	return func(command []string, name string) (cli.Setting, bool) {
+		return settings.Get(strings.Join(append(slices.Clone(command), name), "."))
+	}
+}
+
+// settingKeys returns the keys of the app flags, both global and prefixed by
+// their command path.
+func settingKeys(app *ucli.App) []string {
+	return slices.Sorted(maps.Keys(settingFlags(app)))
+}
+
+// settingFlags returns the flags of every setting key. Global keys set the
+// flags of every command, except the command flags named like an app flag,
+// which are only set by their command keys.
+func settingFlags(app *ucli.App) map[string][]ucli.Flag {
+	keys := map[string][]ucli.Flag{}
+
+	add := func(prefix string, flags []ucli.Flag) {
+		for _, f := range flags {
+			name := f.Names()[0]
+			if name == ucli.HelpFlag.Names()[0] {
+				continue
+			}
+
+			if prefix == "" || !appFlag(app, name) {
+				keys[name] = append(keys[name], f)
+			}
+
+			if prefix != "" {
+				keys[prefix+"."+name] = append(keys[prefix+"."+name], f)
+			}
+		}
+	}
+
+	var walk func(prefix string, cmds []*ucli.Command)
+
+	walk = func(prefix string, cmds []*ucli.Command) {
+		for _, cmd := range cmds {
+			path := cmd.Name
+			if prefix != "" {
+				path = prefix + "." + cmd.Name
+			}
+
+			add(path, cmd.Flags)
+			walk(path, cmd.Subcommands)
+		}
+	}
+
+	add("", app.Flags)
+	walk("", app.Commands)
+
+	return keys
+}
+
+// checkSettingKey checks the key refers to a flag of the app.
+func checkSettingKey(app *ucli.App, key string) error {
+	if _, ok := settingFlags(app)[key]; !ok {
+		return errors.Errorf("unknown setting %q", key)
+	}
+
+	return nil
+}
+
+// checkSettingValues checks the values are valid for every flag of the key,
+// only slice flags take several values.
+func checkSettingValues(app *ucli.App, key string, values []string) error {
+	flags, ok := settingFlags(app)[key]
+	if !ok {
+		return errors.Errorf("unknown setting %q", key)
+	}
+
+	for _, f := range flags {
+		if sf, ok := f.(ucli.DocGenerationSliceFlag); len(values) > 1 && (!ok || !sf.IsSliceFlag()) {
+			return errors.Errorf("setting %q takes a single value", key)
+		}
+
+		set := flag.NewFlagSet(key, flag.ContinueOnError)
+		set.SetOutput(io.Discard)
+
+		if err := f.Apply(set); err != nil {
+			return err
+		}
+
+		name := f.Names()[0]
+
+		for _, v := range values {
+			if err := set.Set(name, v); err != nil {
+				return errors.Errorf("invalid value %q of setting %q: %w", v, key, err)
+			}
+		}
+	}
+
+	return nil
+}
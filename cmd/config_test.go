@@ -0,0 +1,48 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package cmd
+
+import (
+	"testing"
+
+	ucli "github.com/urfave/cli/v2"
+)
+
+func TestConfig_checkSettingValues(t *testing.T) {
+	app := &ucli.App{
+		Name:     "imt",
+		Flags:    []ucli.Flag{&ucli.StringFlag{Name: "config"}},
+		Commands: []*ucli.Command{albumCmd, daemonCmd},
+	}
+
+	cases := []struct {
+		name   string
+		key    string
+		values []string
+		valid  bool
+	}{
+		{"bool", "album.auto-create.explain", []string{"true"}, true},
+		{"invalid bool", "album.auto-create.explain", []string{"maybe"}, false},
+		{"slice", "album.auto-create.exclude", []string{"@eaDir", "#recycle"}, true},
+		{"several values", "album.auto-create.original-path", []string{"/a", "/b"}, false},
+		{"global config", "config", []string{"auth.json"}, true},
+		{"command config", "daemon.config", []string{"daemon.yaml"}, true},
+		{"unknown", "album.auto-create.unknown", []string{"x"}, false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkSettingValues(app, tc.key, tc.values)
+			if valid := err == nil; valid != tc.valid {
+				t.Errorf("unexpected valid: %v (expected %v): %v", valid, tc.valid, err)
+			}
+		})
+	}
+
+	if flags := settingFlags(app)["config"]; len(flags) != 1 {
+		t.Errorf("unexpected flags of config: %d (expected 1)", len(flags))
+	}
+}
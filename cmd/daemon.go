@@ -0,0 +1,74 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package cmd
+
+import (
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	ucli "github.com/urfave/cli/v2"
+
+	"github.com/faabiosr/imt/internal/cli"
+	"github.com/faabiosr/imt/internal/client"
+	"github.com/faabiosr/imt/internal/errors"
+)
+
+var daemonCmd = &ucli.Command{
+	Name:        "daemon",
+	Description: "Runs the jobs of a config file on cron schedules",
+	Flags: []ucli.Flag{
+		&ucli.StringFlag{
+			Name:  "config",
+			Usage: "daemon config file (json, yaml or toml)",
+		},
+		&ucli.StringFlag{
+			Name:  "listen",
+			Usage: "address of the /healthz endpoint, overrides the config file",
+		},
+		&ucli.StringFlag{
+			Name:  "log-format",
+			Usage: "log format: json or text",
+			Value: "json",
+		},
+	},
+	Action: withClient(func(cc *ucli.Context, cl *client.Client) error {
+		if cc.String("config") == "" {
+			return errors.New("Empty config is not allowed")
+		}
+
+		cfg, err := cli.LoadDaemonConfig(cc.String("config"))
+		if err != nil {
+			return err
+		}
+
+		if cc.IsSet("listen") {
+			cfg.Listen = cc.String("listen")
+		}
+
+		var handler slog.Handler
+
+		switch cc.String("log-format") {
+		case "json":
+			handler = slog.NewJSONHandler(cc.App.Writer, nil)
+		case "text":
+			handler = slog.NewTextHandler(cc.App.Writer, nil)
+		default:
+			return errors.Errorf("invalid log format %q, must be json or text", cc.String("log-format"))
+		}
+
+		d, err := cli.NewDaemon(cl, cfg, slog.New(handler))
+		if err != nil {
+			return err
+		}
+
+		ctx, stop := signal.NotifyContext(cc.Context, os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		return d.Run(ctx)
+	}),
+}
@@ -0,0 +1,55 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package cmd
+
+import (
+	"flag"
+	"fmt"
+
+	ucli "github.com/urfave/cli/v2"
+)
+
+// literalSliceFlag is a string slice flag keeping every value as given, not
+// split on commas nor trimmed, for the values holding patterns.
+type literalSliceFlag struct {
+	*ucli.StringSliceFlag
+}
+
+// Apply registers a new value of the flag into the flag set.
+func (f *literalSliceFlag) Apply(set *flag.FlagSet) error {
+	value := &literalSlice{}
+
+	for _, name := range f.Names() {
+		set.Var(value, name, f.Usage)
+	}
+
+	return nil
+}
+
+// literalSlice is the value of the literal slice flags.
+type literalSlice []string
+
+// Set appends the value.
+func (s *literalSlice) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+// String returns the values.
+func (s *literalSlice) String() string {
+	return fmt.Sprint([]string(*s))
+}
+
+// Get returns the values.
+func (s *literalSlice) Get() any {
+	return []string(*s)
+}
+
+// literalSliceValue returns the values of the literal slice flag.
+func literalSliceValue(cc *ucli.Context, name string) []string {
+	values, _ := cc.Value(name).([]string)
+	return values
+}
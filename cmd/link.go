@@ -0,0 +1,190 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/pterm/pterm"
+	"github.com/skip2/go-qrcode"
+	ucli "github.com/urfave/cli/v2"
+
+	"github.com/faabiosr/imt/internal/cli"
+	"github.com/faabiosr/imt/internal/client"
+)
+
+var linkCmd = &ucli.Command{
+	Name:        "link",
+	Description: "Manage shared links",
+	Subcommands: commands(createLink, listLinks, revokeLinks),
+}
+
+var createLink = &ucli.Command{
+	Name:        "create",
+	Description: "creates a shared link of an album, printing its URL",
+	Flags: []ucli.Flag{
+		&ucli.StringFlag{
+			Name:  "album",
+			Usage: "album name or ID to share",
+		},
+		&ucli.StringFlag{
+			Name:  "expires",
+			Usage: "expiration of the link, as a number of days (7d) or a duration (12h), never expires by default",
+		},
+		&ucli.BoolFlag{
+			Name:  "password",
+			Usage: "protects the link with a password, asked interactively",
+		},
+		&ucli.BoolFlag{
+			Name:  "allow-download",
+			Usage: "allows downloading the assets",
+		},
+		&ucli.BoolFlag{
+			Name:  "allow-upload",
+			Usage: "allows uploading assets into the album",
+		},
+		&ucli.BoolFlag{
+			Name:  "qr",
+			Usage: "renders the link as a QR code",
+		},
+	},
+	Action: withClient(func(cc *ucli.Context, cl *client.Client) error {
+		if cc.String("album") == "" {
+			return errors.New("Empty album name is not allowed")
+		}
+
+		opts := &cli.CreateSharedLinkOptions{
+			Album:         cc.String("album"),
+			AllowDownload: cc.Bool("allow-download"),
+			AllowUpload:   cc.Bool("allow-upload"),
+		}
+
+		if cc.IsSet("expires") {
+			d, err := cli.ParseLinkExpiration(cc.String("expires"))
+			if err != nil {
+				return err
+			}
+
+			expiresAt := time.Now().Add(d)
+			opts.ExpiresAt = &expiresAt
+		}
+
+		if cc.Bool("password") {
+			input := pterm.DefaultInteractiveTextInput.WithMask("*")
+
+			password, err := input.Show("Enter link password")
+			if err != nil {
+				return err
+			}
+
+			opts.Password = password
+		}
+
+		link, err := cli.CreateSharedLink(cc.Context, cl, opts)
+		if err != nil {
+			return err
+		}
+
+		u, err := linkURL(cc, link.Key)
+		if err != nil {
+			return err
+		}
+
+		if _, err := fmt.Fprintln(cc.App.Writer, u); err != nil {
+			return err
+		}
+
+		if !cc.Bool("qr") {
+			return nil
+		}
+
+		qr, err := qrcode.New(u, qrcode.Medium)
+		if err != nil {
+			return err
+		}
+
+		_, err = fmt.Fprint(cc.App.Writer, qr.ToSmallString(false))
+
+		return err
+	}),
+}
+
+var listLinks = &ucli.Command{
+	Name:        "list",
+	Description: "lists the shared links",
+	Action: withClient(func(cc *ucli.Context, cl *client.Client) error {
+		links, err := cli.FetchSharedLinks(cc.Context, cl)
+		if err != nil {
+			return err
+		}
+
+		data := pterm.TableData{
+			{"ID", "ALBUM", "URL", "EXPIRES", "DOWNLOAD", "UPLOAD", "PASSWORD"},
+		}
+
+		for _, l := range links {
+			album := "-"
+			if l.Album != nil {
+				album = l.Album.Name
+			}
+
+			u, err := linkURL(cc, l.Key)
+			if err != nil {
+				return err
+			}
+
+			expires := "never"
+			if l.ExpiresAt != nil {
+				expires = l.ExpiresAt.Local().Format(time.DateTime)
+			}
+
+			data = append(data, []string{l.ID, album, u, expires, yesNo(l.AllowDownload), yesNo(l.AllowUpload), yesNo(l.Password != "")})
+		}
+
+		return pterm.DefaultTable.
+			WithHasHeader().
+			WithData(data).
+			Render()
+	}),
+}
+
+var revokeLinks = &ucli.Command{
+	Name:        "revoke",
+	Description: "revokes shared links, by ID (see imt link list)",
+	ArgsUsage:   "<id>...",
+	Action: withClient(func(cc *ucli.Context, cl *client.Client) error {
+		ids := cc.Args().Slice()
+
+		if err := cli.RevokeSharedLinks(cc.Context, cl, ids); err != nil {
+			return err
+		}
+
+		_, err := fmt.Fprintf(cc.App.Writer, "%d links revoked\n", len(ids))
+
+		return err
+	}),
+}
+
+// linkURL returns the URL of a shared link on the host of the credentials.
+func linkURL(cc *ucli.Context, key string) (string, error) {
+	creds, err := cli.Session(rootContext(cc).String("config"))
+	if err != nil {
+		return "", err
+	}
+
+	return cli.SharedLinkURL(creds.Host, key)
+}
+
+// yesNo formats a boolean as yes or no.
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+
+	return "no"
+}
@@ -96,9 +96,24 @@ func newCmd() *ucli.App {
 		},
 	}
 
-	app.Before = func(_ *ucli.Context) error {
+	app.Metadata = map[string]any{}
+
+	app.Before = func(cc *ucli.Context) error {
 		pterm.DisableColor()
-		return nil
+
+		name, err := cli.DefaultSettingsPath()
+		if err != nil {
+			return err
+		}
+
+		settings, err := cli.LoadSettings(name)
+		if err != nil {
+			return err
+		}
+
+		cc.App.Metadata[settingsKey] = settings
+
+		return applySettings(cc, cc.App.Flags, nil)
 	}
 
 	app.Action = func(cc *ucli.Context) error {
@@ -108,17 +123,19 @@ func newCmd() *ucli.App {
 		return nil
 	}
 
-	app.Commands = commands(loginCmd, logoutCmd, infoCmd, albumCmd)
+	app.Commands = commands(loginCmd, logoutCmd, infoCmd, assetCmd, albumCmd, linkCmd, configCmd, daemonCmd)
 
 	return app
 }
 
-// commands sets custom help templates and default values.
+// commands sets custom help templates and default values, flags not set are
+// filled by the user settings.
 func commands(cmds ...*ucli.Command) []*ucli.Command {
 	for _, cmd := range cmds {
 		cmd.Usage = cmd.Description
 		cmd.HideHelpCommand = true
 		cmd.CustomHelpTemplate = commandHelpTemplate
+		cmd.Before = withSettings(cmd.Before)
 	}
 
 	return cmds
@@ -140,7 +157,7 @@ type action func(*ucli.Context, *client.Client) error
 // withClient wraps action func with internal/client.
 func withClient(fn action) ucli.ActionFunc {
 	return func(cc *ucli.Context) error {
-		creds, err := cli.Session(cc.String("config"))
+		creds, err := cli.Session(rootContext(cc).String("config"))
 		if err != nil {
 			return err
 		}
@@ -153,6 +170,20 @@ func withClient(fn action) ucli.ActionFunc {
 	}
 }
 
+// rootContext returns the context of the app flags, which are shadowed by
+// command flags with the same name, like the daemon config.
+func rootContext(cc *ucli.Context) *ucli.Context {
+	lineage := cc.Lineage()
+
+	for i := len(lineage) - 1; i >= 0; i-- {
+		if lineage[i].Command != nil {
+			return lineage[i]
+		}
+	}
+
+	return cc
+}
+
 const kvSize = 2
 
 // pairs reads flag string slice as a key/value pairs.
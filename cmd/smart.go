@@ -0,0 +1,189 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/pterm/pterm"
+	ucli "github.com/urfave/cli/v2"
+
+	"github.com/faabiosr/imt/internal/cli"
+	"github.com/faabiosr/imt/internal/client"
+	"github.com/faabiosr/imt/internal/errors"
+)
+
+var smartAlbums = &ucli.Command{
+	Name:        "smart",
+	Description: "manages albums defined by asset searches",
+	Flags: []ucli.Flag{
+		&ucli.StringFlag{
+			Name:  "definitions",
+			Usage: "smart albums definitions file",
+			Value: must(cli.DefaultSmartAlbumsPath()),
+		},
+	},
+	Subcommands: commands(createSmartAlbum, refreshSmartAlbums, listSmartAlbums, deleteSmartAlbum),
+}
+
+var createSmartAlbum = &ucli.Command{
+	Name:        "create",
+	Description: "stores the search of an album and adds the assets matched",
+	ArgsUsage:   "<album>",
+	Flags:       searchFlags,
+	Action: withClient(func(cc *ucli.Context, cl *client.Client) error {
+		if cc.Args().Len() != 1 {
+			return errors.New("Empty album name is not allowed")
+		}
+
+		search, err := assetSearch(cc)
+		if err != nil {
+			return err
+		}
+
+		name := cc.String("definitions")
+
+		albums, err := cli.LoadSmartAlbums(name)
+		if err != nil {
+			return err
+		}
+
+		album := cli.SmartAlbum{Name: cc.Args().First(), Search: *search}
+
+		if albums, err = cli.AddSmartAlbum(albums, album); err != nil {
+			return err
+		}
+
+		if err := cli.SaveSmartAlbums(name, albums); err != nil {
+			return err
+		}
+
+		return refreshSmartAlbumsAction(cc, cl, []cli.SmartAlbum{album}, false)
+	}),
+}
+
+var refreshSmartAlbums = &ucli.Command{
+	Name:        "refresh",
+	Description: "runs the searches of the smart albums, adding the new assets matched",
+	ArgsUsage:   "[album...]",
+	Flags: []ucli.Flag{
+		&ucli.BoolFlag{
+			Name:  "remove",
+			Usage: "remove the album assets no longer matched",
+		},
+	},
+	Action: withClient(func(cc *ucli.Context, cl *client.Client) error {
+		albums, err := cli.LoadSmartAlbums(cc.String("definitions"))
+		if err != nil {
+			return err
+		}
+
+		if albums, err = cli.SelectSmartAlbums(albums, cc.Args().Slice()); err != nil {
+			return err
+		}
+
+		return refreshSmartAlbumsAction(cc, cl, albums, cc.Bool("remove"))
+	}),
+}
+
+// refreshSmartAlbumsAction refreshes the smart albums and renders a report.
+func refreshSmartAlbumsAction(cc *ucli.Context, cl *client.Client, albums []cli.SmartAlbum, remove bool) error {
+	spin, err := spinner(cc.App.Writer, "refreshing smart albums...").Start()
+	if err != nil {
+		return err
+	}
+
+	reports, refreshErr := cli.RefreshSmartAlbums(cc.Context, cl, albums, remove)
+
+	if err := spin.Stop(); err != nil {
+		return err
+	}
+
+	data := pterm.TableData{
+		{"ALBUM", "CREATED", "ASSETS ADDED", "ASSETS REMOVED", "STATUS"},
+	}
+
+	for _, r := range reports {
+		created := "no"
+		if r.Created {
+			created = "yes"
+		}
+
+		status := "ok"
+		if r.Err != nil {
+			status = r.Err.Error()
+		}
+
+		data = append(data, []string{r.Album, created, strconv.Itoa(r.Added), strconv.Itoa(r.Removed), status})
+	}
+
+	if err := pterm.DefaultTable.WithHasHeader().WithData(data).Render(); err != nil {
+		return err
+	}
+
+	return refreshErr
+}
+
+var listSmartAlbums = &ucli.Command{
+	Name:        "list",
+	Description: "lists the smart albums and their searches",
+	Action: func(cc *ucli.Context) error {
+		albums, err := cli.LoadSmartAlbums(cc.String("definitions"))
+		if err != nil {
+			return err
+		}
+
+		data := pterm.TableData{
+			{"ALBUM", "SEARCH"},
+		}
+
+		for _, a := range albums {
+			search, err := json.Marshal(a.Search)
+			if err != nil {
+				return err
+			}
+
+			data = append(data, []string{a.Name, string(search)})
+		}
+
+		return pterm.DefaultTable.
+			WithHasHeader().
+			WithData(data).
+			Render()
+	},
+}
+
+var deleteSmartAlbum = &ucli.Command{
+	Name:        "delete",
+	Description: "deletes the definition of a smart album, keeping the album",
+	ArgsUsage:   "<album>",
+	Action: func(cc *ucli.Context) error {
+		if cc.Args().Len() != 1 {
+			return errors.New("Empty album name is not allowed")
+		}
+
+		name := cc.String("definitions")
+
+		albums, err := cli.LoadSmartAlbums(name)
+		if err != nil {
+			return err
+		}
+
+		if albums, err = cli.DeleteSmartAlbum(albums, cc.Args().First()); err != nil {
+			return err
+		}
+
+		if err := cli.SaveSmartAlbums(name, albums); err != nil {
+			return err
+		}
+
+		_, err = fmt.Fprintf(cc.App.Writer, "smart album %q deleted\n", cc.Args().First())
+
+		return err
+	},
+}
@@ -8,27 +8,57 @@ package cli
 import (
 	"context"
 	"fmt"
-	"io/fs"
 	"net/http"
 	"net/url"
 	"os"
-	"path/filepath"
 	"regexp"
 	"slices"
 	"strings"
 
 	"github.com/faabiosr/imt/internal/client"
+	"github.com/faabiosr/imt/internal/errors"
 )
 
 // AutoCreateAlbumOptions handles the options to auto create albums.
 type AutoCreateAlbumsOptions struct {
-	Folder            string            `json:"folder"`
-	Recursive         bool              `json:"recursive"`
-	SkipLevels        int               `json:"skip_levels"`
-	OriginalPath      string            `json:"original_path,omitempty"`
-	Exclude           []string          `json:"exclude,omitempty"`
-	ParentGroupAssets bool              `json:"parent_group_assets"`
-	Albums            map[string]string `json:"albums,omitempty"`
+	Folder            string             `json:"folder"`
+	Recursive         bool               `json:"recursive"`
+	SkipLevels        int                `json:"skip_levels"`
+	OriginalPath      string             `json:"original_path,omitempty"`
+	PathMappings      []PathMapping      `json:"path_mappings,omitempty"`
+	Exclude           []string           `json:"exclude,omitempty"`
+	Include           []string           `json:"include,omitempty"`
+	MinAssets         int                `json:"min_assets,omitempty"`
+	Media             *MediaFilter       `json:"media,omitempty"`
+	LegacyGlobs       bool               `json:"legacy_globs,omitempty"`
+	ExcludeIgnoreCase bool               `json:"exclude_ignore_case,omitempty"`
+	NoIgnoreFiles     bool               `json:"no_ignore_files,omitempty"`
+	FollowSymlinks    bool               `json:"follow_symlinks,omitempty"`
+	ParentGroupAssets bool               `json:"parent_group_assets"`
+	Albums            map[string]string  `json:"albums,omitempty"`
+	NameTemplate      string             `json:"name_template,omitempty"`
+	RenameRules       []RenameRule       `json:"rename_rules,omitempty"`
+	Dates             *FolderDateOptions `json:"dates,omitempty"`
+	OnConflict        string             `json:"on_conflict,omitempty" enum:"merge,suffix,parent-prefix,fail"`
+	Normalize         []string           `json:"normalize,omitempty" enum:"nfc,casefold,whitespace"`
+	Remote            bool               `json:"remote,omitempty"`
+	Share             []string           `json:"share,omitempty"`
+}
+
+// AutoCreateAlbumsReport holds the outcome of the albums auto creation.
+type AutoCreateAlbumsReport struct {
+	Folders    []string
+	Created    []string
+	Assets     int
+	Collisions []AlbumCollision
+	Skipped    []SkippedFolder
+}
+
+// SkippedFolder represents a folder left out for having fewer assets than
+// the minimum set.
+type SkippedFolder struct {
+	Folder string
+	Assets int
 }
 
 // Album represents an Album stored in Immich.
@@ -42,12 +72,66 @@ type Album struct {
 type Albums []Album
 
 // AutoCreateAlbums will create albums based on folders.
-func AutoCreateAlbums(ctx context.Context, cl *client.Client, opts *AutoCreateAlbumsOptions) error {
-	groups, err := groupAlbums(opts)
+func AutoCreateAlbums(ctx context.Context, cl *client.Client, opts *AutoCreateAlbumsOptions) (*AutoCreateAlbumsReport, error) {
+	report := &AutoCreateAlbumsReport{}
+
+	if err := opts.Media.validate(); err != nil {
+		return report, err
+	}
+
+	namer, err := newAlbumNamer(opts)
+	if err != nil {
+		return report, err
+	}
+
+	paths, err := listFolders(ctx, cl, opts)
+	if err != nil {
+		return report, err
+	}
+
+	return report, syncAlbums(ctx, cl, opts, namer, paths, nil, report)
+}
+
+// syncAlbums creates the albums of the folders and adds their assets. When
+// changed is set, the album names are resolved for every folder, but only the
+// changed folders are synced.
+func syncAlbums(
+	ctx context.Context,
+	cl *client.Client,
+	opts *AutoCreateAlbumsOptions,
+	namer *albumNamer,
+	paths []string,
+	changed func(path string) bool,
+	report *AutoCreateAlbumsReport,
+) error {
+	shares, err := parseAlbumShares(opts.Share)
 	if err != nil {
 		return err
 	}
 
+	groups, err := groupAlbums(opts, namer, paths)
+	if err != nil {
+		return err
+	}
+
+	report.Collisions = namer.owners.collisions
+
+	if changed != nil {
+		groups = changedGroups(groups, changed)
+
+		report.Collisions = slices.DeleteFunc(report.Collisions, func(c AlbumCollision) bool {
+			_, ok := groups[c.Album]
+			return !ok
+		})
+	}
+
+	for _, folders := range groups {
+		report.Folders = append(report.Folders, folders...)
+	}
+
+	slices.Sort(report.Folders)
+	report.Folders = slices.Compact(report.Folders)
+
 	if len(groups) == 0 {
 		return nil
 	}
@@ -57,49 +141,164 @@ func AutoCreateAlbums(ctx context.Context, cl *client.Client, opts *AutoCreateAl
 		return err
 	}
 
+	assets, err := folderAssets(ctx, cl, opts, groups, report)
+	if err != nil {
+		return err
+	}
+
+	var users []map[string]string
+
+	if len(shares) > 0 {
+		if users, err = resolveAlbumShares(ctx, cl, shares); err != nil {
+			return err
+		}
+	}
+
 	items := make(map[string][]string)
 
-	for name, folders := range groups {
-		i := slices.IndexFunc(as, func(a Album) bool {
-			return a.Name == name
+	for _, name := range namer.sorted(groups) {
+		folders := slices.DeleteFunc(slices.Clone(groups[name]), func(f string) bool {
+			_, ok := assets[f]
+			return !ok
 		})
 
-		if i >= 0 {
-			items[as[i].ID] = append(items[as[i].ID], folders...)
+		if len(folders) == 0 {
 			continue
 		}
 
-		a, err := createAlbum(ctx, cl, name)
+		existing := slices.DeleteFunc(slices.Clone(as), func(a Album) bool {
+			return namer.key(a.Name) != namer.key(name)
+		})
+
+		c, err := existingCollision(opts.OnConflict, name, existing)
 		if err != nil {
 			return err
 		}
 
-		items[a.ID] = folders
-		as = append(as, a)
+		if c != nil {
+			report.Collisions = append(report.Collisions, *c)
+		}
+
+		id := ""
+
+		if len(existing) > 0 {
+			id = existing[0].ID
+		} else {
+			a, err := createAlbum(ctx, cl, name, namer.description(name))
+			if err != nil {
+				return err
+			}
+
+			if len(users) > 0 {
+				if err := addAlbumUsers(ctx, cl, a.ID, users); err != nil {
+					return err
+				}
+			}
+
+			id = a.ID
+			as = append(as, a)
+			report.Created = append(report.Created, name)
+		}
+
+		for _, f := range folders {
+			items[id] = append(items[id], assetIDs(assets[f])...)
+		}
 	}
 
-	for id, paths := range items {
-		assets, err := fetchAssetsIDsByOriginalPaths(ctx, cl, paths)
+	failed := bulkIDResults{}
+
+	for id, ids := range items {
+		slices.Sort(ids)
+		ids = slices.Compact(ids)
+
+		if len(ids) == 0 {
+			continue
+		}
+
+		results, err := addAssetsToAlbum(ctx, cl, id, ids)
 		if err != nil {
 			return err
 		}
 
-		if err := addAssetsToAlbum(ctx, cl, id, assets); err != nil {
-			return err
+		report.Assets += results.added()
+		failed = append(failed, results.failed()...)
+	}
+
+	return failed.err()
+}
+
+// changedGroups keeps only the changed folders of the groups, dropping the
+// groups left without folders.
+func changedGroups(groups map[string][]string, changed func(path string) bool) map[string][]string {
+	filtered := map[string][]string{}
+
+	for name, folders := range groups {
+		folders = slices.DeleteFunc(slices.Clone(folders), func(f string) bool {
+			return !changed(f)
+		})
+
+		if len(folders) > 0 {
+			filtered[name] = folders
 		}
 	}
 
-	return nil
+	return filtered
 }
 
-// createAlbum creates an album with name.
-func createAlbum(ctx context.Context, cl *client.Client, name string) (Album, error) {
+// folderAssets fetches the assets of every folder grouped, keeping only the
+// assets matching the media filter. Folders with fewer assets than the
+// minimum are left out and reported as skipped.
+func folderAssets(
+	ctx context.Context,
+	cl *client.Client,
+	opts *AutoCreateAlbumsOptions,
+	groups map[string][]string,
+	report *AutoCreateAlbumsReport,
+) (map[string][]Asset, error) {
+	var paths []string
+	for _, folders := range groups {
+		paths = append(paths, folders...)
+	}
+
+	slices.Sort(paths)
+
+	assets, err := fetchAssetsByOriginalPaths(ctx, cl, slices.Compact(paths))
+	if err != nil {
+		return assets, err
+	}
+
+	for path, list := range assets {
+		list = opts.Media.filter(list)
+
+		if len(list) < opts.MinAssets {
+			delete(assets, path)
+			report.Skipped = append(report.Skipped, SkippedFolder{Folder: path, Assets: len(list)})
+
+			continue
+		}
+
+		assets[path] = list
+	}
+
+	slices.SortFunc(report.Skipped, func(a, b SkippedFolder) int {
+		return strings.Compare(a.Folder, b.Folder)
+	})
+
+	return assets, nil
+}
+
+// createAlbum creates an album with name and an optional description.
+func createAlbum(ctx context.Context, cl *client.Client, name, description string) (Album, error) {
 	resource, _ := url.Parse("/api/albums")
 
 	body := map[string]string{
 		"albumName": name,
 	}
 
+	if description != "" {
+		body["description"] = description
+	}
+
 	a := Album{}
 
 	req, err := cl.NewRequest(ctx, http.MethodPost, resource, body)
@@ -124,113 +323,303 @@ func FetchAlbums(ctx context.Context, cl *client.Client) (Albums, error) {
 	return as, cl.Do(req, &as)
 }
 
-// addAssetsToAlbum adds a list of assets into an album.
-func addAssetsToAlbum(ctx context.Context, cl *client.Client, id string, assets []string) error {
-	resource, _ := url.Parse(fmt.Sprintf("/api/albums/%s/assets", id))
-	body := map[string]any{
-		"ids": assets,
+// find returns the album of the ID or name, a name shared by several albums
+// must be referenced by ID.
+func (as Albums) find(ref string) (Album, error) {
+	var found []Album
+
+	for _, a := range as {
+		if a.ID == ref {
+			return a, nil
+		}
+
+		if a.Name == ref {
+			found = append(found, a)
+		}
 	}
 
-	req, err := cl.NewRequest(ctx, http.MethodPut, resource, body)
-	if err != nil {
-		return err
+	switch len(found) {
+	case 0:
+		return Album{}, errors.Errorf("album %q not found", ref)
+	case 1:
+		return found[0], nil
+	default:
+		return Album{}, errors.Errorf("album name %q is shared by %d albums, use the album ID", ref, len(found))
+	}
+}
+
+// Errors of the assets not added into an album.
+const (
+	bulkErrorDuplicate = "duplicate"
+	bulkErrorNotFound  = "not_found"
+)
+
+// bulkIDResult is the outcome of an asset added into an album.
+type bulkIDResult struct {
+	ID      string `json:"id"`
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+}
+
+// bulkIDResults are the outcomes of the assets added into an album.
+type bulkIDResults []bulkIDResult
+
+// added returns the number of assets added, the duplicates were already in the
+// album.
+func (rs bulkIDResults) added() int {
+	n := 0
+
+	for _, r := range rs {
+		if r.Success {
+			n++
+		}
 	}
 
-	return cl.Do(req, nil)
+	return n
 }
 
-// excludeFilter apply a glob/regexp filter to remove folders path.
-func excludeFilter(excludes []string) (func(path string) bool, error) {
-	rules := make([]*regexp.Regexp, 0, len(excludes))
-	fn := func(string) bool { return false }
+// failed returns the assets not added, skipping the duplicates.
+func (rs bulkIDResults) failed() bulkIDResults {
+	return slices.DeleteFunc(slices.Clone(rs), func(r bulkIDResult) bool {
+		return r.Success || r.Error == bulkErrorDuplicate
+	})
+}
 
-	for _, e := range excludes {
-		r, err := globToRegexp(e)
+// err returns the errors of the assets, nil when there are none.
+func (rs bulkIDResults) err() error {
+	errs := make([]error, 0, len(rs))
+	for _, r := range rs {
+		errs = append(errs, errors.Errorf("asset %q: %s", r.ID, r.Error))
+	}
+
+	return errors.Join(errs...)
+}
+
+// addAssetsToAlbum adds a list of assets into an album in batches, returning
+// the outcome of every asset.
+func addAssetsToAlbum(ctx context.Context, cl *client.Client, id string, assets []string) (bulkIDResults, error) {
+	resource, _ := url.Parse(fmt.Sprintf("/api/albums/%s/assets", id))
+
+	results := bulkIDResults{}
+
+	for batch := range slices.Chunk(assets, albumAssetsBatchSize) {
+		body := map[string]any{
+			"ids": batch,
+		}
+
+		req, err := cl.NewRequest(ctx, http.MethodPut, resource, body)
 		if err != nil {
-			return fn, err
+			return results, err
 		}
 
-		rules = append(rules, r)
+		var batchResults bulkIDResults
+		if err := cl.Do(req, &batchResults); err != nil {
+			return results, err
+		}
+
+		results = append(results, batchResults...)
 	}
 
-	return func(path string) bool {
-		for _, r := range rules {
-			if r.MatchString(path) {
-				return true
-			}
-		}
+	return results, nil
+}
 
-		return false
-	}, nil
+// Exclude pattern prefixes, placed after the "!" negation.
+const (
+	patternIgnoreCase     = "i:"
+	patternIgnoreCaseFlag = "(?i)"
+	patternRegexp         = "re:"
+)
+
+// globOptions handles how the exclude patterns are compiled.
+type globOptions struct {
+	legacy     bool
+	ignoreCase bool
+}
+
+// excludeGlobs returns the options to compile the exclude patterns.
+func (o *AutoCreateAlbumsOptions) excludeGlobs() globOptions {
+	return globOptions{legacy: o.LegacyGlobs, ignoreCase: o.ExcludeIgnoreCase}
+}
+
+// excludeRule is a compiled exclude pattern. Rooted rules match the paths
+// relative to the folder root.
+type excludeRule struct {
+	re     *regexp.Regexp
+	negate bool
+	rooted bool
 }
 
-// groupAlbums reads the folder tree and group albums by folder name.
-// Internally also exclude folders that should not be created as album based on
-// the options set.
-func groupAlbums(opts *AutoCreateAlbumsOptions) (map[string][]string, error) {
-	folder := filepath.Dir(opts.Folder)
-	depth := strings.Count(folder, string(os.PathSeparator))
+// excludeRules is an ordered list of exclude patterns.
+type excludeRules []excludeRule
+
+// newExcludeRules compiles the exclude patterns. When anchored, patterns with
+// a slash before the last character only match from the start of the path,
+// like the patterns of ignore files.
+func newExcludeRules(patterns []string, g globOptions, anchored bool) (excludeRules, error) {
+	rules := make(excludeRules, 0, len(patterns))
+
+	for _, p := range patterns {
+		r, err := newExcludeRule(p, g, anchored)
+		if err != nil {
+			return nil, errors.Errorf("invalid pattern %q: %w", p, err)
+		}
 
-	op := ""
-	if opts.OriginalPath != "" {
-		op = filepath.Dir(opts.OriginalPath)
+		rules = append(rules, r)
 	}
 
-	albums := map[string][]string{}
+	return rules, nil
+}
 
-	excludes, err := excludeFilter(opts.Exclude)
-	if err != nil {
-		return albums, err
+// newExcludeRule compiles an exclude pattern. The pattern may be prefixed by
+// "i:" or "(?i)" to ignore case and by "re:" to be a regular expression
+// matched against the path instead of a glob.
+func newExcludeRule(p string, g globOptions, anchored bool) (excludeRule, error) {
+	pattern, negate := strings.CutPrefix(p, "!")
+	ignoreCase, raw := g.ignoreCase, false
+
+prefixes:
+	for {
+		switch {
+		case strings.HasPrefix(pattern, patternIgnoreCase):
+			pattern, ignoreCase = pattern[len(patternIgnoreCase):], true
+		case strings.HasPrefix(pattern, patternIgnoreCaseFlag):
+			pattern, ignoreCase = pattern[len(patternIgnoreCaseFlag):], true
+		case !raw && strings.HasPrefix(pattern, patternRegexp):
+			pattern, raw = pattern[len(patternRegexp):], true
+		default:
+			break prefixes
+		}
 	}
 
-	err = filepath.WalkDir(folder, func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			return err
+	if pattern == "" {
+		return excludeRule{}, errors.New("empty pattern")
+	}
+
+	expr := pattern
+	rooted := false
+
+	if !raw {
+		anchor := anchored &&
+			!strings.HasPrefix(pattern, "**") &&
+			strings.Contains(strings.TrimSuffix(pattern, "/"), "/")
+
+		if anchor && !strings.HasPrefix(pattern, "/") {
+			pattern = "/" + pattern
 		}
 
-		if !opts.Recursive {
-			if d.IsDir() && strings.Count(path, string(os.PathSeparator)) > depth+1 {
-				return fs.SkipDir
-			}
+		r, err := globToRegexp(pattern, g.legacy)
+		if err != nil {
+			return excludeRule{}, err
 		}
 
-		if !d.IsDir() {
-			return nil
+		// like gitignore, a leading slash anchors the pattern to the root.
+		rooted = !anchored && !g.legacy && strings.HasPrefix(pattern, "/")
+
+		expr = r.String()
+		if anchor || rooted {
+			expr = "^" + expr
 		}
+	}
 
-		path = strings.Replace(path, folder, op, 1)
+	if ignoreCase {
+		expr = "(?i)" + expr
+	}
 
-		if excludes(path) {
-			return nil
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return excludeRule{}, errors.Errorf("bad regular expression: %w", err)
+	}
+
+	return excludeRule{re: re, negate: negate, rooted: rooted}, nil
+}
+
+// match evaluates the rules in order, the last one matching the path wins.
+// Rooted rules are matched against the relative path, and the excluded value
+// is kept when no rule matches.
+func (rules excludeRules) match(path, rel string, excluded bool) bool {
+	for _, r := range rules {
+		p := path
+		if r.rooted {
+			p = rel
 		}
 
-		segments := strings.Split(path, string(os.PathSeparator))
-		skip := opts.SkipLevels + 1
-		if len(segments) <= skip {
-			return nil
+		if r.re.MatchString(p) {
+			excluded = !r.negate
 		}
+	}
 
-		segments = segments[skip:]
+	return excluded
+}
 
+// excludeFilter apply a glob/regexp filter to remove folders path. Patterns
+// prefixed by "!" re-include the folders matched, the rules are evaluated in
+// order and the last one matching the path wins. Patterns starting with a
+// slash match the path converted by rel, relative to the folder root.
+func excludeFilter(excludes []string, g globOptions, rel func(path string) string) (func(path string) bool, error) {
+	fn := func(string) bool { return false }
+
+	rules, err := newExcludeRules(excludes, g, false)
+	if err != nil {
+		return fn, err
+	}
+
+	return func(path string) bool {
+		return rules.match(path, rel(path), false)
+	}, nil
+}
+
+// ExplainAlbumNames reads the folder tree like AutoCreateAlbums does and
+// returns how the album name of every folder was resolved, without changing
+// any album.
+func ExplainAlbumNames(ctx context.Context, cl *client.Client, opts *AutoCreateAlbumsOptions) ([]AlbumNameExplanation, error) {
+	namer, err := newAlbumNamer(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	paths, err := listFolders(ctx, cl, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := groupAlbums(opts, namer, paths); err != nil {
+		return nil, err
+	}
+
+	return namer.explanations, nil
+}
+
+// groupAlbums groups the folders by album name, the paths must be sorted as
+// a folder tree walk, parents before children.
+func groupAlbums(opts *AutoCreateAlbumsOptions, namer *albumNamer, paths []string) (map[string][]string, error) {
+	albums := map[string][]string{}
+
+	for _, path := range paths {
+		// the first segment is the root, it is always skipped.
+		segments := strings.Split(path, string(os.PathSeparator))[1:]
+		if len(segments) <= opts.SkipLevels {
+			continue
+		}
+
+		start := opts.SkipLevels
 		if !opts.ParentGroupAssets {
-			segments = segments[len(segments)-1:]
+			start = len(segments) - 1
 		}
 
-		for _, s := range segments {
-			if v, ok := opts.Albums[s]; ok {
-				s = v
+		for i := start; i < len(segments); i++ {
+			s, err := namer.name(newNameData(path, segments, i, opts.SkipLevels))
+			if err != nil {
+				return albums, err
 			}
 
 			if _, ok := albums[s]; !ok {
 				albums[s] = append(albums[s], path)
-				return nil
+				break
 			}
 
 			albums[s] = append(albums[s], path)
 		}
+	}
 
-		return nil
-	})
-
-	return albums, err
+	return albums, nil
 }
@@ -0,0 +1,368 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package cli
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/faabiosr/imt/internal/client"
+	"github.com/faabiosr/imt/internal/errors"
+)
+
+// albumAssetsBatchSize is the number of assets added into or removed from an
+// album by request.
+const albumAssetsBatchSize = 500
+
+// assetIDPattern matches the asset IDs, which are UUIDs.
+var assetIDPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// AddAlbumAssetsOptions handles the options to add a list of assets into an
+// album. Entries are asset IDs, original paths as seen by Immich, or local
+// paths translated by the path mappings. Relative paths are relative to the
+// original path when set.
+type AddAlbumAssetsOptions struct {
+	Album        string
+	Entries      []string
+	OriginalPath string
+	PathMappings []PathMapping
+}
+
+// AddAlbumAssetsReport holds the outcome of adding a list of assets into an
+// album.
+type AddAlbumAssetsReport struct {
+	Created    bool
+	Assets     int
+	Unresolved []string
+}
+
+// ReadAlbumEntries reads one entry per line, skipping empty lines and the
+// lines starting with "#".
+func ReadAlbumEntries(r io.Reader) ([]string, error) {
+	entries := []string{}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		entries = append(entries, line)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return entries, fmt.Errorf("unable to read entries: %w", err)
+	}
+
+	return entries, nil
+}
+
+// AddAlbumAssets resolves the entries into asset IDs and adds them into the
+// album, referenced by ID or name, which is created when missing. Paths without assets and IDs not found
+// by the server are reported as unresolved, and the assets already in the album
+// are not counted.
+func AddAlbumAssets(ctx context.Context, cl *client.Client, opts *AddAlbumAssetsOptions) (*AddAlbumAssetsReport, error) {
+	report := &AddAlbumAssetsReport{}
+
+	if opts.Album == "" {
+		return report, errors.New("album name is required")
+	}
+
+	ids, paths, err := albumEntries(opts)
+	if err != nil {
+		return report, err
+	}
+
+	resolved, err := resolveAssetPaths(ctx, cl, paths)
+	if err != nil {
+		return report, err
+	}
+
+	for _, entry := range opts.Entries {
+		path, ok := paths[entry]
+		if !ok {
+			continue
+		}
+
+		id, ok := resolved[path]
+		if !ok {
+			report.Unresolved = append(report.Unresolved, entry)
+			continue
+		}
+
+		if _, ok := ids[id]; !ok {
+			ids[id] = entry
+		}
+	}
+
+	if len(ids) == 0 {
+		return report, nil
+	}
+
+	as, err := FetchAlbums(ctx, cl)
+	if err != nil {
+		return report, err
+	}
+
+	a, err := as.find(opts.Album)
+	if err != nil {
+		// the album is only missing when no album has the name, otherwise the
+		// name is shared by several albums.
+		if slices.ContainsFunc(as, func(a Album) bool { return a.Name == opts.Album }) {
+			return report, err
+		}
+
+		if a, err = createAlbum(ctx, cl, opts.Album, ""); err != nil {
+			return report, err
+		}
+
+		report.Created = true
+	}
+
+	results, err := addAssetsToAlbum(ctx, cl, a.ID, slices.Sorted(maps.Keys(ids)))
+	if err != nil {
+		return report, err
+	}
+
+	report.Assets = results.added()
+
+	var failed bulkIDResults
+
+	for _, r := range results.failed() {
+		if r.Error == bulkErrorNotFound {
+			report.Unresolved = append(report.Unresolved, ids[r.ID])
+			continue
+		}
+
+		failed = append(failed, r)
+	}
+
+	return report, failed.err()
+}
+
+// albumEntries splits the entries into asset IDs and paths. The IDs are indexed
+// by ID with their entry, and the paths are indexed by entry and translated
+// into the paths seen by Immich.
+func albumEntries(opts *AddAlbumAssetsOptions) (map[string]string, map[string]string, error) {
+	mapper, err := newPathMappings(opts.PathMappings)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ids := make(map[string]string)
+	paths := make(map[string]string)
+
+	for _, entry := range opts.Entries {
+		if assetIDPattern.MatchString(entry) {
+			id := strings.ToLower(entry)
+			if _, ok := ids[id]; !ok {
+				ids[id] = entry
+			}
+
+			continue
+		}
+
+		path := entry
+
+		if !filepath.IsAbs(path) {
+			if opts.OriginalPath != "" {
+				paths[entry] = filepath.Join(opts.OriginalPath, path)
+				continue
+			}
+
+			if path, err = filepath.Abs(path); err != nil {
+				return nil, nil, fmt.Errorf("unable to resolve path %q: %w", entry, err)
+			}
+		}
+
+		// paths not covered by any mapping are original paths.
+		if server, err := mapper.server(filepath.Clean(path)); err == nil {
+			path = server
+		}
+
+		paths[entry] = filepath.Clean(path)
+	}
+
+	return ids, paths, nil
+}
+
+// resolveAssetPaths returns the asset IDs of the original paths, fetching the
+// assets of their folders.
+func resolveAssetPaths(ctx context.Context, cl *client.Client, paths map[string]string) (map[string]string, error) {
+	folders := []string{}
+	for _, path := range paths {
+		folders = append(folders, filepath.Dir(path))
+	}
+
+	slices.Sort(folders)
+	folders = slices.Compact(folders)
+
+	assets, err := fetchAssetsByOriginalPaths(ctx, cl, folders)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved := make(map[string]string)
+
+	for _, folder := range folders {
+		for _, a := range assets[folder] {
+			resolved[a.OriginalPath] = a.ID
+		}
+	}
+
+	return resolved, nil
+}
+
+// RemoveAlbumAssetsOptions handles the options to remove assets from an
+// album. Assets matching any of the original paths, which are file paths or
+// folder prefixes, globs or IDs are removed.
+type RemoveAlbumAssetsOptions struct {
+	Album  string
+	Paths  []string
+	Globs  []string
+	IDs    []string
+	DryRun bool
+}
+
+// RemoveAlbumAssets removes the album assets matching the options, returning
+// the assets matched. On dry run, the assets are not removed.
+func RemoveAlbumAssets(ctx context.Context, cl *client.Client, opts *RemoveAlbumAssetsOptions) ([]Asset, error) {
+	matched := []Asset{}
+
+	if len(opts.Paths) == 0 && len(opts.Globs) == 0 && len(opts.IDs) == 0 {
+		return matched, errors.New("at least one path, glob or ID is required")
+	}
+
+	match, err := albumAssetsFilter(opts)
+	if err != nil {
+		return matched, err
+	}
+
+	as, err := FetchAlbums(ctx, cl)
+	if err != nil {
+		return matched, err
+	}
+
+	album, err := as.find(opts.Album)
+	if err != nil {
+		return matched, err
+	}
+
+	assets, err := fetchAlbumAssets(ctx, cl, album.ID)
+	if err != nil {
+		return matched, err
+	}
+
+	for _, a := range assets {
+		if match(a) {
+			matched = append(matched, a)
+		}
+	}
+
+	if opts.DryRun || len(matched) == 0 {
+		return matched, nil
+	}
+
+	return matched, removeAssetsFromAlbum(ctx, cl, album.ID, assetIDs(matched))
+}
+
+// albumAssetsFilter returns a filter matching the assets of any of the paths,
+// globs or IDs.
+func albumAssetsFilter(opts *RemoveAlbumAssetsOptions) (func(a Asset) bool, error) {
+	// original paths are absolute, rooted globs match from the start.
+	globs, err := excludeFilter(opts.Globs, globOptions{}, func(p string) string { return p })
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make(map[string]bool, len(opts.IDs))
+	for _, id := range opts.IDs {
+		ids[strings.ToLower(id)] = true
+	}
+
+	return func(a Asset) bool {
+		if ids[strings.ToLower(a.ID)] || globs(a.OriginalPath) {
+			return true
+		}
+
+		return slices.ContainsFunc(opts.Paths, func(p string) bool {
+			_, ok := cutPathPrefix(a.OriginalPath, filepath.Clean(p))
+			return ok
+		})
+	}, nil
+}
+
+// albumDetails represents an album with its assets and shared users.
+type albumDetails struct {
+	Album
+	Description string      `json:"description"`
+	OwnerID     string      `json:"ownerId"`
+	Owner       User        `json:"owner"`
+	AlbumUsers  []albumUser `json:"albumUsers"`
+	Assets      []Asset     `json:"assets"`
+}
+
+// albumUser represents a user the album is shared with.
+type albumUser struct {
+	User User   `json:"user"`
+	Role string `json:"role"`
+}
+
+// fetchAlbum returns the album with its assets and shared users.
+func fetchAlbum(ctx context.Context, cl *client.Client, id string) (*albumDetails, error) {
+	resource, _ := url.Parse(fmt.Sprintf("/api/albums/%s", id))
+
+	album := &albumDetails{}
+
+	req, err := cl.NewRequest(ctx, http.MethodGet, resource, nil)
+	if err != nil {
+		return album, err
+	}
+
+	return album, cl.Do(req, album)
+}
+
+// fetchAlbumAssets returns the assets of the album.
+func fetchAlbumAssets(ctx context.Context, cl *client.Client, id string) ([]Asset, error) {
+	album, err := fetchAlbum(ctx, cl, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return album.Assets, nil
+}
+
+// removeAssetsFromAlbum removes a list of assets from an album, in batches.
+func removeAssetsFromAlbum(ctx context.Context, cl *client.Client, id string, assets []string) error {
+	resource, _ := url.Parse(fmt.Sprintf("/api/albums/%s/assets", id))
+
+	for batch := range slices.Chunk(assets, albumAssetsBatchSize) {
+		body := map[string]any{
+			"ids": batch,
+		}
+
+		req, err := cl.NewRequest(ctx, http.MethodDelete, resource, body)
+		if err != nil {
+			return err
+		}
+
+		if err := cl.Do(req, nil); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
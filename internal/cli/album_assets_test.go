@@ -0,0 +1,404 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+
+	"github.com/faabiosr/imt/internal/client"
+)
+
+func TestAlbumAssets_ReadAlbumEntries(t *testing.T) {
+	entries, err := ReadAlbumEntries(strings.NewReader("# exported\n/photos/a.jpg\n\n  b.jpg  \n"))
+	if err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+
+	expected := []string{"/photos/a.jpg", "b.jpg"}
+
+	if !reflect.DeepEqual(entries, expected) {
+		t.Errorf("unexpected entries: %v (expected %v)", entries, expected)
+	}
+}
+
+func TestAlbumAssets_AddAlbumAssets(t *testing.T) {
+	t.Run("invalid path mapping", func(t *testing.T) {
+		opts := &AddAlbumAssetsOptions{
+			Album:        "Lisbon",
+			Entries:      []string{"/photos/a.jpg"},
+			PathMappings: []PathMapping{{Local: "/mnt/photos"}},
+		}
+
+		if _, err := AddAlbumAssets(context.Background(), nil, opts); err == nil {
+			t.Error("expected an error, got nil")
+		}
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		hc := http.DefaultClient
+
+		httpmock.ActivateNonDefault(hc)
+		defer httpmock.DeactivateAndReset()
+
+		httpmock.RegisterResponder(
+			http.MethodGet,
+			testHost+"/api/view/folder",
+			httpmock.NewJsonResponderOrPanic(http.StatusBadRequest, json.RawMessage(`{"message": "invalid path"}`)),
+		)
+
+		baseURL, _ := url.Parse(testHost)
+		cl := client.NewWithHTTPClient(baseURL, testAPIKey, hc)
+
+		opts := &AddAlbumAssetsOptions{Album: "Lisbon", Entries: []string{"/photos/a.jpg"}}
+
+		if _, err := AddAlbumAssets(context.Background(), cl, opts); err == nil {
+			t.Error("expected an error, got nil")
+		}
+	})
+
+	t.Run("resolves entries", func(t *testing.T) {
+		hc := http.DefaultClient
+
+		httpmock.ActivateNonDefault(hc)
+		defer httpmock.DeactivateAndReset()
+
+		folders := map[string]string{
+			"/external/2023":      `[{"id": "1", "originalPath": "/external/2023/a.jpg"}, {"id": "2", "originalPath": "/external/2023/b.jpg"}]`,
+			"/external/2023/best": `[{"id": "3", "originalPath": "/external/2023/best/c.jpg"}]`,
+			"/other":              `[]`,
+		}
+
+		httpmock.RegisterResponder(
+			http.MethodGet,
+			testHost+"/api/view/folder",
+			func(req *http.Request) (*http.Response, error) {
+				return httpmock.NewStringResponse(http.StatusOK, folders[req.URL.Query().Get("path")]), nil
+			},
+		)
+
+		httpmock.RegisterResponder(
+			http.MethodGet,
+			testHost+"/api/albums",
+			httpmock.NewStringResponder(http.StatusOK, `[{"id": "a1", "albumName": "Porto"}]`),
+		)
+
+		httpmock.RegisterResponder(
+			http.MethodPost,
+			testHost+"/api/albums",
+			httpmock.NewStringResponder(http.StatusCreated, `{"id": "a2", "albumName": "Lisbon"}`),
+		)
+
+		var added []string
+
+		// the assets not listed are added.
+		errs := map[string]string{
+			"3":                                    "duplicate",
+			"9f000000-0000-4000-8000-000000000000": "not_found",
+			"9f000000-0000-4000-8000-000000000001": "no_permission",
+		}
+
+		httpmock.RegisterResponder(
+			http.MethodPut,
+			testHost+"/api/albums/a2/assets",
+			func(req *http.Request) (*http.Response, error) {
+				var body map[string][]string
+				if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+					return nil, err
+				}
+
+				added = body["ids"]
+
+				results := []bulkIDResult{}
+				for _, id := range added {
+					results = append(results, bulkIDResult{ID: id, Success: errs[id] == "", Error: errs[id]})
+				}
+
+				return httpmock.NewJsonResponse(http.StatusOK, results)
+			},
+		)
+
+		baseURL, _ := url.Parse(testHost)
+		cl := client.NewWithHTTPClient(baseURL, testAPIKey, hc)
+
+		opts := &AddAlbumAssetsOptions{
+			Album: "Lisbon",
+			Entries: []string{
+				"/external/2023/a.jpg",
+				"/mnt/photos/2023/best/c.jpg",
+				"1B2C3D4E-0000-4000-8000-000000000000",
+				"/other/missing.jpg",
+				"9F000000-0000-4000-8000-000000000000",
+			},
+			PathMappings: []PathMapping{{Local: "/mnt/photos", Server: "/external"}},
+		}
+
+		report, err := AddAlbumAssets(context.Background(), cl, opts)
+		if err != nil {
+			t.Fatalf("expected nil, got %v", err)
+		}
+
+		expected := &AddAlbumAssetsReport{
+			Created:    true,
+			Assets:     2,
+			Unresolved: []string{"/other/missing.jpg", "9F000000-0000-4000-8000-000000000000"},
+		}
+
+		if !reflect.DeepEqual(report, expected) {
+			t.Errorf("unexpected report: %+v (expected %+v)", report, expected)
+		}
+
+		ids := []string{"1", "1b2c3d4e-0000-4000-8000-000000000000", "3", "9f000000-0000-4000-8000-000000000000"}
+		if !reflect.DeepEqual(added, ids) {
+			t.Errorf("unexpected assets added: %v (expected %v)", added, ids)
+		}
+
+		opts.Entries = []string{"/external/2023/b.jpg", "9f000000-0000-4000-8000-000000000001"}
+
+		report, err = AddAlbumAssets(context.Background(), cl, opts)
+		if err == nil || !strings.Contains(err.Error(), "no_permission") {
+			t.Errorf("unexpected error: %v (expected no_permission)", err)
+		}
+
+		if report.Assets != 1 || len(report.Unresolved) != 0 {
+			t.Errorf("unexpected report: %+v", report)
+		}
+	})
+
+	t.Run("adds in batches", func(t *testing.T) {
+		hc := http.DefaultClient
+
+		httpmock.ActivateNonDefault(hc)
+		defer httpmock.DeactivateAndReset()
+
+		httpmock.RegisterResponder(
+			http.MethodGet,
+			testHost+"/api/albums",
+			httpmock.NewStringResponder(http.StatusOK, `[{"id": "a1", "albumName": "Lisbon"}, {"id": "a2", "albumName": "Porto"}]`),
+		)
+
+		var batches []int
+
+		httpmock.RegisterResponder(
+			http.MethodPut,
+			testHost+"/api/albums/a1/assets",
+			func(req *http.Request) (*http.Response, error) {
+				var body map[string][]string
+				if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+					return nil, err
+				}
+
+				batches = append(batches, len(body["ids"]))
+
+				results := []bulkIDResult{}
+				for _, id := range body["ids"] {
+					results = append(results, bulkIDResult{ID: id, Success: true})
+				}
+
+				return httpmock.NewJsonResponse(http.StatusOK, results)
+			},
+		)
+
+		baseURL, _ := url.Parse(testHost)
+		cl := client.NewWithHTTPClient(baseURL, testAPIKey, hc)
+
+		opts := &AddAlbumAssetsOptions{Album: "a1"}
+
+		for i := range albumAssetsBatchSize + 1 {
+			opts.Entries = append(opts.Entries, fmt.Sprintf("00000000-0000-4000-8000-%012d", i))
+		}
+
+		report, err := AddAlbumAssets(context.Background(), cl, opts)
+		if err != nil {
+			t.Fatalf("expected nil, got %v", err)
+		}
+
+		if report.Created || report.Assets != albumAssetsBatchSize+1 {
+			t.Errorf("unexpected report: %+v", report)
+		}
+
+		if expected := []int{albumAssetsBatchSize, 1}; !reflect.DeepEqual(batches, expected) {
+			t.Errorf("unexpected batches: %v (expected %v)", batches, expected)
+		}
+	})
+
+	t.Run("album name shared", func(t *testing.T) {
+		hc := http.DefaultClient
+
+		httpmock.ActivateNonDefault(hc)
+		defer httpmock.DeactivateAndReset()
+
+		httpmock.RegisterResponder(
+			http.MethodGet,
+			testHost+"/api/albums",
+			httpmock.NewStringResponder(http.StatusOK, `[{"id": "a1", "albumName": "Lisbon"}, {"id": "a2", "albumName": "Lisbon"}]`),
+		)
+
+		baseURL, _ := url.Parse(testHost)
+		cl := client.NewWithHTTPClient(baseURL, testAPIKey, hc)
+
+		opts := &AddAlbumAssetsOptions{Album: "Lisbon", Entries: []string{"1b2c3d4e-0000-4000-8000-000000000000"}}
+
+		report, err := AddAlbumAssets(context.Background(), cl, opts)
+		if err == nil || !strings.Contains(err.Error(), "shared by 2 albums") {
+			t.Errorf("unexpected error: %v (expected shared by 2 albums)", err)
+		}
+
+		if report.Created {
+			t.Errorf("unexpected report: %+v", report)
+		}
+	})
+
+	t.Run("relative paths", func(t *testing.T) {
+		opts := &AddAlbumAssetsOptions{
+			Entries:      []string{"2023/a.jpg", "./2023/../2024/b.jpg"},
+			OriginalPath: "/external/photos",
+		}
+
+		_, paths, err := albumEntries(opts)
+		if err != nil {
+			t.Fatalf("expected nil, got %v", err)
+		}
+
+		expected := map[string]string{
+			"2023/a.jpg":           "/external/photos/2023/a.jpg",
+			"./2023/../2024/b.jpg": "/external/photos/2024/b.jpg",
+		}
+
+		if !reflect.DeepEqual(paths, expected) {
+			t.Errorf("unexpected paths: %v (expected %v)", paths, expected)
+		}
+	})
+}
+
+func TestAlbumAssets_RemoveAlbumAssets(t *testing.T) {
+	t.Run("missing filters", func(t *testing.T) {
+		if _, err := RemoveAlbumAssets(context.Background(), nil, &RemoveAlbumAssetsOptions{Album: "Lisbon"}); err == nil {
+			t.Error("expected an error, got nil")
+		}
+	})
+
+	t.Run("invalid glob", func(t *testing.T) {
+		opts := &RemoveAlbumAssetsOptions{Album: "Lisbon", Globs: []string{"re:["}}
+
+		if _, err := RemoveAlbumAssets(context.Background(), nil, opts); err == nil {
+			t.Error("expected an error, got nil")
+		}
+	})
+
+	t.Run("album not found", func(t *testing.T) {
+		hc := http.DefaultClient
+
+		httpmock.ActivateNonDefault(hc)
+		defer httpmock.DeactivateAndReset()
+
+		httpmock.RegisterResponder(
+			http.MethodGet,
+			testHost+"/api/albums",
+			httpmock.NewStringResponder(http.StatusOK, `[{"id": "a1", "albumName": "Porto"}]`),
+		)
+
+		baseURL, _ := url.Parse(testHost)
+		cl := client.NewWithHTTPClient(baseURL, testAPIKey, hc)
+
+		opts := &RemoveAlbumAssetsOptions{Album: "Lisbon", IDs: []string{"1"}}
+
+		if _, err := RemoveAlbumAssets(context.Background(), cl, opts); err == nil {
+			t.Error("expected an error, got nil")
+		}
+	})
+
+	t.Run("removes in batches", func(t *testing.T) {
+		hc := http.DefaultClient
+
+		httpmock.ActivateNonDefault(hc)
+		defer httpmock.DeactivateAndReset()
+
+		httpmock.RegisterResponder(
+			http.MethodGet,
+			testHost+"/api/albums",
+			httpmock.NewStringResponder(http.StatusOK, `[{"id": "a1", "albumName": "Lisbon"}]`),
+		)
+
+		assets := []string{
+			`{"id": "1", "originalPath": "/photos/2023/a.jpg"}`,
+			`{"id": "2", "originalPath": "/photos/2023/Raw/b.cr2"}`,
+			`{"id": "3", "originalPath": "/photos/2024/c.jpg"}`,
+			`{"id": "4", "originalPath": "/photos/2024/d.jpg"}`,
+			`{"id": "5", "originalPath": "/photos/20234/e.jpg"}`,
+		}
+
+		for i := range albumAssetsBatchSize {
+			assets = append(assets, `{"id": "x`+strconv.Itoa(i)+`", "originalPath": "/photos/2025/`+strconv.Itoa(i)+`.jpg"}`)
+		}
+
+		httpmock.RegisterResponder(
+			http.MethodGet,
+			testHost+"/api/albums/a1",
+			httpmock.NewStringResponder(http.StatusOK, `{"id": "a1", "assets": [`+strings.Join(assets, ",")+`]}`),
+		)
+
+		var batches []int
+
+		httpmock.RegisterResponder(
+			http.MethodDelete,
+			testHost+"/api/albums/a1/assets",
+			func(req *http.Request) (*http.Response, error) {
+				var body map[string][]any
+				if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+					return nil, err
+				}
+
+				batches = append(batches, len(body["ids"]))
+
+				return httpmock.NewStringResponse(http.StatusOK, `[]`), nil
+			},
+		)
+
+		baseURL, _ := url.Parse(testHost)
+		cl := client.NewWithHTTPClient(baseURL, testAPIKey, hc)
+
+		opts := &RemoveAlbumAssetsOptions{
+			Album: "Lisbon",
+			Paths: []string{"/photos/2023/", "/photos/2025"},
+			Globs: []string{"**/*.cr2"},
+			IDs:   []string{"4"},
+		}
+
+		dryRun := *opts
+		dryRun.DryRun = true
+
+		matched, err := RemoveAlbumAssets(context.Background(), cl, &dryRun)
+		if err != nil {
+			t.Fatalf("expected nil, got %v", err)
+		}
+
+		if len(matched) != albumAssetsBatchSize+3 || len(batches) != 0 {
+			t.Errorf("unexpected dry run: %d assets matched, %d batches removed", len(matched), len(batches))
+		}
+
+		if ids := assetIDs(matched[:3]); !reflect.DeepEqual(ids, []string{"1", "2", "4"}) {
+			t.Errorf("unexpected assets matched: %v (expected [1 2 4])", ids)
+		}
+
+		if _, err := RemoveAlbumAssets(context.Background(), cl, opts); err != nil {
+			t.Fatalf("expected nil, got %v", err)
+		}
+
+		if expected := []int{albumAssetsBatchSize, 3}; !reflect.DeepEqual(batches, expected) {
+			t.Errorf("unexpected batches: %v (expected %v)", batches, expected)
+		}
+	})
+}
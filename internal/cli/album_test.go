@@ -7,6 +7,7 @@ import (
 	"net/url"
 	"os"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/jarcoal/httpmock"
@@ -16,7 +17,7 @@ import (
 
 func TestAlbum_excludeFilter(t *testing.T) {
 	t.Run("failure", func(t *testing.T) {
-		exclude, err := excludeFilter([]string{"***"})
+		exclude, err := excludeFilter([]string{"***"}, globOptions{}, relTo("/media"))
 		if err == nil {
 			t.Error("expected an error, got nil")
 		}
@@ -27,7 +28,7 @@ func TestAlbum_excludeFilter(t *testing.T) {
 	})
 
 	t.Run("success match", func(t *testing.T) {
-		exclude, err := excludeFilter([]string{"/trip/*"})
+		exclude, err := excludeFilter([]string{"/trip/*"}, globOptions{}, relTo("/media"))
 		if err != nil {
 			t.Errorf("expected nil, got %v", err)
 		}
@@ -38,7 +39,7 @@ func TestAlbum_excludeFilter(t *testing.T) {
 	})
 
 	t.Run("success not match", func(t *testing.T) {
-		exclude, err := excludeFilter([]string{"/trip/*"})
+		exclude, err := excludeFilter([]string{"/trip/*"}, globOptions{}, relTo("/media"))
 		if err != nil {
 			t.Errorf("expected nil, got %v", err)
 		}
@@ -47,6 +48,140 @@ func TestAlbum_excludeFilter(t *testing.T) {
 			t.Error("expected false, got true")
 		}
 	})
+
+	t.Run("gitignore semantics", func(t *testing.T) {
+		tests := []struct {
+			patterns []string
+			path     string
+			want     bool
+		}{
+			{[]string{"Screenshots"}, "/2023/Screenshots", true},
+			{[]string{"Screenshots"}, "/2023/Screenshots/old", true},
+			{[]string{"Screenshots"}, "/2023/MyScreenshots", false},
+			{[]string{"**/Screenshots/**"}, "/2023/Screenshots", false},
+			{[]string{"**/Screenshots/**"}, "/2023/Screenshots/old", true},
+			{[]string{"**/Screenshots/**"}, "/Screenshots/old/deep", true},
+			{[]string{"/2023/*"}, "/media/2023/Beach", true},
+			{[]string{"/2023/*"}, "/media/2023", false},
+			{[]string{"/2023/*"}, "/media/old/2023/Beach", false},
+			{[]string{"/2023/*"}, "/other/2023/Beach", false},
+			{[]string{"2023/*"}, "/media/old/2023/Beach", true},
+			{[]string{"/2023/*/Raw"}, "/media/2023/Beach/Raw", true},
+			{[]string{"/2023/*/Raw"}, "/media/2023/Beach/day1/Raw", false},
+			{[]string{"/2023/**/Raw"}, "/media/2023/Raw", true},
+			{[]string{"/2023/**/Raw"}, "/media/2023/Beach/day1/Raw", true},
+			{[]string{"/2023/**/Raw"}, "/media/old/2023/Raw", false},
+			{[]string{"/2023/?each"}, "/media/2023/Beach", true},
+			{[]string{"/2023/?each"}, "/media/2023//each", false},
+			{[]string{"/2023/**", "!/2023/Best"}, "/media/2023/Beach", true},
+			{[]string{"/2023/**", "!/2023/Best"}, "/media/2023/Best", false},
+			{[]string{"/2023/**", "!/2023/Best"}, "/media/2023/Best/Raw", false},
+			{[]string{"/2023/**", "!/2023/Best", "Raw"}, "/media/2023/Best/Raw", true},
+			{[]string{"!/2023/Best", "/2023/**"}, "/media/2023/Best", true},
+			{[]string{"!/2023"}, "/media/2023", false},
+		}
+
+		for _, tt := range tests {
+			exclude, err := excludeFilter(tt.patterns, globOptions{}, relTo("/media"))
+			if err != nil {
+				t.Errorf("expected nil, got %v", err)
+			}
+
+			if got := exclude(tt.path); got != tt.want {
+				t.Errorf("unexpected exclude of %s by %v: %t (expected %t)", tt.path, tt.patterns, got, tt.want)
+			}
+		}
+	})
+
+	t.Run("legacy globs", func(t *testing.T) {
+		exclude, err := excludeFilter([]string{"/trip*"}, globOptions{legacy: true}, relTo("/media"))
+		if err != nil {
+			t.Errorf("expected nil, got %v", err)
+		}
+
+		if !exclude("/media/trips/2023/img_001.jpg") {
+			t.Error("expected true, got false")
+		}
+
+		if _, err := excludeFilter([]string{"**/trip"}, globOptions{legacy: true}, relTo("/media")); err == nil {
+			t.Error("expected an error, got nil")
+		}
+	})
+
+	t.Run("matching modes", func(t *testing.T) {
+		tests := []struct {
+			patterns   []string
+			ignoreCase bool
+			path       string
+			want       bool
+		}{
+			{[]string{"Raw"}, false, "/2023/RAW", false},
+			{[]string{"i:Raw"}, false, "/2023/RAW", true},
+			{[]string{"(?i)raw"}, false, "/2023/Raw/day1", true},
+			{[]string{"!i:raw"}, false, "/2023/RAW", false},
+			{[]string{"**", "!i:raw"}, false, "/2023/rAw", false},
+			{[]string{"Raw"}, true, "/2023/raw", true},
+			{[]string{"re:/20[0-9]{2}/raw$"}, false, "/media/2023/raw", true},
+			{[]string{"re:/20[0-9]{2}/raw$"}, false, "/media/2023/RAW", false},
+			{[]string{"i:re:/20[0-9]{2}/raw$"}, false, "/media/2023/RAW", true},
+			{[]string{"re:(?i)/raw$"}, false, "/media/2023/RAW", true},
+			{[]string{"re:/raw$"}, true, "/media/2023/Raw", true},
+			{[]string{"/20{{[0-9]{2}}}/raw"}, true, "/media/2023/RAW/day1", true},
+		}
+
+		for _, tt := range tests {
+			exclude, err := excludeFilter(tt.patterns, globOptions{ignoreCase: tt.ignoreCase}, relTo("/media"))
+			if err != nil {
+				t.Errorf("expected nil, got %v", err)
+			}
+
+			if got := exclude(tt.path); got != tt.want {
+				t.Errorf("unexpected exclude of %s by %v: %t (expected %t)", tt.path, tt.patterns, got, tt.want)
+			}
+		}
+	})
+
+	t.Run("invalid patterns", func(t *testing.T) {
+		tests := []struct {
+			pattern string
+			err     string
+		}{
+			{"re:(", `invalid pattern "re:(": bad regular expression`},
+			{"i:", `invalid pattern "i:": empty pattern`},
+			{"!re:", `invalid pattern "!re:": empty pattern`},
+			{"i:[a", `invalid pattern "i:[a": mismatched '[' and ']'`},
+		}
+
+		for _, tt := range tests {
+			_, err := excludeFilter([]string{"ok", tt.pattern}, globOptions{}, relTo("/media"))
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("unexpected error: %v (expected %s)", err, tt.err)
+			}
+		}
+	})
+}
+
+// relTo returns a function converting paths into paths relative to the root.
+func relTo(root string) func(string) string {
+	return func(path string) string {
+		rel, _ := cutPathPrefix(path, root)
+		return rel
+	}
+}
+
+// localAlbums walks the local folder tree grouping the folders by album name.
+func localAlbums(opts *AutoCreateAlbumsOptions) (map[string][]string, error) {
+	namer, err := newAlbumNamer(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	paths, err := listFolders(context.Background(), nil, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return groupAlbums(opts, namer, paths)
 }
 
 func TestAlbum_groupAlbums(t *testing.T) {
@@ -68,7 +203,7 @@ func TestAlbum_groupAlbums(t *testing.T) {
 			Folder: tmp + string(os.PathSeparator),
 		}
 
-		albums, err := groupAlbums(opts)
+		albums, err := localAlbums(opts)
 		if err != nil {
 			t.Errorf("expected nil, got %v", err)
 		}
@@ -111,7 +246,7 @@ func TestAlbum_groupAlbums(t *testing.T) {
 			},
 		}
 
-		albums, err := groupAlbums(opts)
+		albums, err := localAlbums(opts)
 		if err != nil {
 			t.Errorf("expected nil, got %v", err)
 		}
@@ -133,6 +268,280 @@ func TestAlbum_groupAlbums(t *testing.T) {
 			t.Errorf("unexpected albums: '%v' (expected '%v')", albums, expected)
 		}
 	})
+
+	t.Run("path mappings", func(t *testing.T) {
+		tmp := t.TempDir()
+		for _, dir := range []string{"/nas1/2023/Beach/", "/nas2/Trip/"} {
+			if err := os.MkdirAll(tmp+dir, 0o755); err != nil {
+				t.Fatalf("expected nil, got %v", err)
+			}
+		}
+
+		opts := &AutoCreateAlbumsOptions{
+			Folder:     tmp + string(os.PathSeparator),
+			Recursive:  true,
+			SkipLevels: 2,
+			PathMappings: []PathMapping{
+				{Local: tmp, Server: "/libraries"},
+				{Local: tmp + "/nas1", Server: "/libraries/one"},
+				{Local: tmp + "/nas2", Server: "/libraries/two"},
+			},
+		}
+
+		albums, err := localAlbums(opts)
+		if err != nil {
+			t.Errorf("expected nil, got %v", err)
+		}
+
+		expected := map[string][]string{
+			"2023":  {"/libraries/one/2023"},
+			"Beach": {"/libraries/one/2023/Beach"},
+			"Trip":  {"/libraries/two/Trip"},
+		}
+
+		if !reflect.DeepEqual(albums, expected) {
+			t.Errorf("unexpected albums: '%v' (expected '%v')", albums, expected)
+		}
+	})
+
+	t.Run("nested path mappings", func(t *testing.T) {
+		tmp := t.TempDir()
+		for _, dir := range []string{"/nas1/2023/Beach/", "/nas2/photos/Trip/"} {
+			if err := os.MkdirAll(tmp+dir, 0o755); err != nil {
+				t.Fatalf("expected nil, got %v", err)
+			}
+		}
+
+		opts := &AutoCreateAlbumsOptions{
+			Folder:    tmp + string(os.PathSeparator),
+			Recursive: true,
+			PathMappings: []PathMapping{
+				{Local: tmp + "/nas1", Server: "/external/library1"},
+				{Local: tmp + "/nas2/photos", Server: "/external/library2"},
+			},
+		}
+
+		albums, err := localAlbums(opts)
+		if err != nil {
+			t.Errorf("expected nil, got %v", err)
+		}
+
+		expected := map[string][]string{
+			"library1": {"/external/library1"},
+			"2023":     {"/external/library1/2023"},
+			"Beach":    {"/external/library1/2023/Beach"},
+			"library2": {"/external/library2"},
+			"Trip":     {"/external/library2/Trip"},
+		}
+
+		if !reflect.DeepEqual(albums, expected) {
+			t.Errorf("unexpected albums: '%v' (expected '%v')", albums, expected)
+		}
+	})
+
+	t.Run("path not covered by mappings", func(t *testing.T) {
+		tmp := t.TempDir()
+		for _, dir := range []string{"/nas1/Beach/", "/other/"} {
+			if err := os.MkdirAll(tmp+dir, 0o755); err != nil {
+				t.Fatalf("expected nil, got %v", err)
+			}
+		}
+
+		opts := &AutoCreateAlbumsOptions{
+			Folder:       tmp + string(os.PathSeparator),
+			Recursive:    true,
+			PathMappings: []PathMapping{{Local: tmp + "/nas1", Server: "/libraries/one"}},
+		}
+
+		_, err := localAlbums(opts)
+		if err == nil || !strings.Contains(err.Error(), "not covered by any path mapping") {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("include patterns", func(t *testing.T) {
+		tmp := t.TempDir()
+		for _, dir := range []string{"/2023/Beach/day1/", "/2023/Screenshots/", "/2024/Beach/"} {
+			if err := os.MkdirAll(tmp+dir, 0o755); err != nil {
+				t.Fatalf("expected nil, got %v", err)
+			}
+		}
+
+		opts := &AutoCreateAlbumsOptions{
+			Folder:    tmp + string(os.PathSeparator),
+			Recursive: true,
+			Include:   []string{"Beach", "!day1"},
+		}
+
+		albums, err := localAlbums(opts)
+		if err != nil {
+			t.Errorf("expected nil, got %v", err)
+		}
+
+		expected := map[string][]string{
+			"Beach": {"/2023/Beach", "/2024/Beach"},
+		}
+
+		if !reflect.DeepEqual(albums, expected) {
+			t.Errorf("unexpected albums: '%v' (expected '%v')", albums, expected)
+		}
+	})
+
+	t.Run("invalid include pattern", func(t *testing.T) {
+		opts := &AutoCreateAlbumsOptions{
+			Folder:  t.TempDir() + string(os.PathSeparator),
+			Include: []string{"***"},
+		}
+
+		if _, err := localAlbums(opts); err == nil {
+			t.Error("expected an error, got nil")
+		}
+	})
+
+	t.Run("name template", func(t *testing.T) {
+		tmp := t.TempDir()
+		if err := os.MkdirAll(tmp+"/2023/07 - Lisbon/day2/", 0o755); err != nil {
+			t.Fatalf("expected nil, got %v", err)
+		}
+
+		opts := &AutoCreateAlbumsOptions{
+			Folder:       tmp + string(os.PathSeparator),
+			Recursive:    true,
+			SkipLevels:   1,
+			NameTemplate: "{{ regex `^(?:\\d{2} - )?(.*)$` .Parent }} ({{ .Date.Format \"2006-01\" }})",
+		}
+
+		albums, err := localAlbums(opts)
+		if err != nil {
+			t.Errorf("expected nil, got %v", err)
+		}
+
+		expected := map[string][]string{
+			"2023 (2023-07)":   {"/2023/07 - Lisbon"},
+			"Lisbon (2023-07)": {"/2023/07 - Lisbon/day2"},
+		}
+
+		if !reflect.DeepEqual(albums, expected) {
+			t.Errorf("unexpected albums: '%v' (expected '%v')", albums, expected)
+		}
+	})
+
+	t.Run("conflict suffix", func(t *testing.T) {
+		tmp := t.TempDir()
+		if err := os.MkdirAll(tmp+"/2022/Beach/", 0o755); err != nil {
+			t.Fatalf("expected nil, got %v", err)
+		}
+
+		if err := os.MkdirAll(tmp+"/2023/Beach/", 0o755); err != nil {
+			t.Fatalf("expected nil, got %v", err)
+		}
+
+		opts := &AutoCreateAlbumsOptions{
+			Folder:     tmp + string(os.PathSeparator),
+			Recursive:  true,
+			SkipLevels: 1,
+			OnConflict: ConflictSuffix,
+		}
+
+		albums, err := localAlbums(opts)
+		if err != nil {
+			t.Errorf("expected nil, got %v", err)
+		}
+
+		expected := map[string][]string{
+			"Beach":     {"/2022/Beach"},
+			"Beach (2)": {"/2023/Beach"},
+		}
+
+		if !reflect.DeepEqual(albums, expected) {
+			t.Errorf("unexpected albums: '%v' (expected '%v')", albums, expected)
+		}
+	})
+
+	t.Run("normalized names", func(t *testing.T) {
+		tmp := t.TempDir()
+		for _, dir := range []string{"/2022/Beach/", "/2023/beach/", "/2023/Cafe\u0301/", "/2024/Caf\u00e9/"} {
+			if err := os.MkdirAll(tmp+dir, 0o755); err != nil {
+				t.Fatalf("expected nil, got %v", err)
+			}
+		}
+
+		opts := &AutoCreateAlbumsOptions{
+			Folder:     tmp + string(os.PathSeparator),
+			Recursive:  true,
+			SkipLevels: 1,
+			Normalize:  []string{NormalizeNFC, NormalizeCaseFold},
+		}
+
+		albums, err := localAlbums(opts)
+		if err != nil {
+			t.Errorf("expected nil, got %v", err)
+		}
+
+		expected := map[string][]string{
+			"Beach":      {"/2022/Beach", "/2023/beach"},
+			"Cafe\u0301": {"/2023/Cafe\u0301", "/2024/Caf\u00e9"},
+		}
+
+		if !reflect.DeepEqual(albums, expected) {
+			t.Errorf("unexpected albums: '%v' (expected '%v')", albums, expected)
+		}
+	})
+
+	t.Run("invalid name template", func(t *testing.T) {
+		opts := &AutoCreateAlbumsOptions{
+			Folder:       t.TempDir() + string(os.PathSeparator),
+			NameTemplate: "{{ .Name",
+		}
+
+		if _, err := localAlbums(opts); err == nil {
+			t.Error("expected an error, got nil")
+		}
+	})
+}
+
+func TestAlbum_ExplainAlbumNames(t *testing.T) {
+	t.Run("invalid rename rule", func(t *testing.T) {
+		opts := &AutoCreateAlbumsOptions{
+			Folder:      t.TempDir() + string(os.PathSeparator),
+			RenameRules: []RenameRule{{Pattern: "["}},
+		}
+
+		if _, err := ExplainAlbumNames(context.Background(), nil, opts); err == nil {
+			t.Error("expected an error, got nil")
+		}
+	})
+
+	t.Run("success", func(t *testing.T) {
+		tmp := t.TempDir()
+		if err := os.MkdirAll(tmp+"/Ana_Dias/", 0o755); err != nil {
+			t.Fatalf("expected nil, got %v", err)
+		}
+
+		if err := os.MkdirAll(tmp+"/Company_Summit/", 0o755); err != nil {
+			t.Fatalf("expected nil, got %v", err)
+		}
+
+		opts := &AutoCreateAlbumsOptions{
+			Folder:      tmp + string(os.PathSeparator),
+			Albums:      map[string]string{"Ana_Dias": "Ana"},
+			RenameRules: []RenameRule{{Pattern: "_", Replacement: " "}},
+		}
+
+		explanations, err := ExplainAlbumNames(context.Background(), nil, opts)
+		if err != nil {
+			t.Errorf("expected nil, got %v", err)
+		}
+
+		expected := []AlbumNameExplanation{
+			{Folder: "/Ana_Dias", Album: "Ana", Steps: []string{"album mapping"}},
+			{Folder: "/Company_Summit", Album: "Company Summit", Steps: []string{`rule #1 ("_" => " ")`}},
+		}
+
+		if !reflect.DeepEqual(explanations, expected) {
+			t.Errorf("unexpected explanations: '%v' (expected '%v')", explanations, expected)
+		}
+	})
 }
 
 func TestAlbumAutoCreateAlbums(t *testing.T) {
@@ -146,7 +555,7 @@ func TestAlbumAutoCreateAlbums(t *testing.T) {
 			Exclude: []string{"***"},
 		}
 
-		err := AutoCreateAlbums(ctx, cl, opts)
+		_, err := AutoCreateAlbums(ctx, cl, opts)
 		if err == nil {
 			t.Error("expected an error, got nil")
 		}
@@ -168,7 +577,7 @@ func TestAlbumAutoCreateAlbums(t *testing.T) {
 			Exclude: []string{"/food*"},
 		}
 
-		err := AutoCreateAlbums(ctx, cl, opts)
+		_, err := AutoCreateAlbums(ctx, cl, opts)
 		if err != nil {
 			t.Errorf("expected nil, got %v", err)
 		}
@@ -202,7 +611,45 @@ func TestAlbumAutoCreateAlbums(t *testing.T) {
 			Folder: tmp + string(os.PathSeparator),
 		}
 
-		err := AutoCreateAlbums(ctx, cl, opts)
+		_, err := AutoCreateAlbums(ctx, cl, opts)
+		if err == nil {
+			t.Error("expected an error, got nil")
+		}
+	})
+
+	t.Run("duplicate existing albums", func(t *testing.T) {
+		ctx := context.Background()
+		hc := http.DefaultClient
+
+		httpmock.ActivateNonDefault(hc)
+		defer httpmock.DeactivateAndReset()
+
+		httpmock.RegisterResponder(
+			http.MethodGet,
+			testHost+"/api/albums",
+			httpmock.NewJsonResponderOrPanic(
+				http.StatusOK,
+				json.RawMessage(`[
+					{"albumName": "food", "id": "821256df-77e9-4616-91b9-57465995a01b"},
+					{"albumName": "food", "id": "4cbd308b-ed70-4fe9-92f3-ad4ac3ee8710"}
+				]`),
+			),
+		)
+
+		baseURL, _ := url.Parse(testHost)
+		cl := client.NewWithHTTPClient(baseURL, testAPIKey, hc)
+
+		tmp := t.TempDir()
+		if err := os.MkdirAll(tmp+"/food/", 0o755); err != nil {
+			t.Fatalf("expected nil, got %v", err)
+		}
+
+		opts := &AutoCreateAlbumsOptions{
+			Folder:     tmp + string(os.PathSeparator),
+			OnConflict: ConflictFail,
+		}
+
+		_, err := AutoCreateAlbums(ctx, cl, opts)
 		if err == nil {
 			t.Error("expected an error, got nil")
 		}
@@ -233,6 +680,15 @@ func TestAlbumAutoCreateAlbums(t *testing.T) {
 			),
 		)
 
+		httpmock.RegisterResponder(
+			http.MethodGet,
+			testHost+"/api/view/folder",
+			httpmock.NewJsonResponderOrPanic(
+				http.StatusOK,
+				json.RawMessage(`[{"id": "dff78948-b5b2-4d04-a493-ad65df879286"}]`),
+			),
+		)
+
 		baseURL, _ := url.Parse(testHost)
 		cl := client.NewWithHTTPClient(baseURL, testAPIKey, hc)
 
@@ -245,7 +701,7 @@ func TestAlbumAutoCreateAlbums(t *testing.T) {
 			Folder: tmp + string(os.PathSeparator),
 		}
 
-		err := AutoCreateAlbums(ctx, cl, opts)
+		_, err := AutoCreateAlbums(ctx, cl, opts)
 		if err == nil {
 			t.Error("expected an error, got nil")
 		}
@@ -297,7 +753,7 @@ func TestAlbumAutoCreateAlbums(t *testing.T) {
 			Folder: tmp + string(os.PathSeparator),
 		}
 
-		err := AutoCreateAlbums(ctx, cl, opts)
+		_, err := AutoCreateAlbums(ctx, cl, opts)
 		if err == nil {
 			t.Error("expected an error, got nil")
 		}
@@ -358,7 +814,7 @@ func TestAlbumAutoCreateAlbums(t *testing.T) {
 			Folder: tmp + string(os.PathSeparator),
 		}
 
-		err := AutoCreateAlbums(ctx, cl, opts)
+		_, err := AutoCreateAlbums(ctx, cl, opts)
 		if err == nil {
 			t.Error("expected an error, got nil")
 		}
@@ -403,7 +859,10 @@ func TestAlbumAutoCreateAlbums(t *testing.T) {
 			testHost+"/api/albums/4cbd308b-ed70-4fe9-92f3-ad4ac3ee8710/assets",
 			httpmock.NewJsonResponderOrPanic(
 				http.StatusOK,
-				json.RawMessage(`[{"id": "821256df-77e9-4616-91b9-57465995a01b"}]`),
+				json.RawMessage(`[
+					{"id": "dff78948-b5b2-4d04-a493-ad65df879286", "success": true},
+					{"id": "8dba92a5-753b-4bee-be4f-f7a59ba20762", "success": true}
+				]`),
 			),
 		)
 
@@ -424,9 +883,376 @@ func TestAlbumAutoCreateAlbums(t *testing.T) {
 			Recursive: true,
 		}
 
-		err := AutoCreateAlbums(ctx, cl, opts)
+		_, err := AutoCreateAlbums(ctx, cl, opts)
 		if err != nil {
 			t.Errorf("expected nil, got %v", err)
 		}
 	})
+
+	t.Run("success with folder dates", func(t *testing.T) {
+		ctx := context.Background()
+		hc := http.DefaultClient
+
+		httpmock.ActivateNonDefault(hc)
+		defer httpmock.DeactivateAndReset()
+
+		httpmock.RegisterResponder(
+			http.MethodGet,
+			testHost+"/api/albums",
+			httpmock.NewJsonResponderOrPanic(http.StatusOK, json.RawMessage(`[]`)),
+		)
+
+		httpmock.RegisterMatcherResponder(
+			http.MethodPost,
+			testHost+"/api/albums",
+			httpmock.BodyContainsString(`"albumName":"Birthday","description":"May 2024"`),
+			httpmock.NewJsonResponderOrPanic(
+				http.StatusOK,
+				json.RawMessage(`{"albumName": "Birthday", "id": "4cbd308b-ed70-4fe9-92f3-ad4ac3ee8710"}`),
+			),
+		)
+
+		httpmock.RegisterResponder(
+			http.MethodGet,
+			testHost+"/api/view/folder",
+			httpmock.NewJsonResponderOrPanic(
+				http.StatusOK,
+				json.RawMessage(`[{"id": "dff78948-b5b2-4d04-a493-ad65df879286"}]`),
+			),
+		)
+
+		httpmock.RegisterResponder(
+			http.MethodPut,
+			testHost+"/api/albums/4cbd308b-ed70-4fe9-92f3-ad4ac3ee8710/assets",
+			httpmock.NewJsonResponderOrPanic(http.StatusOK, json.RawMessage(`[]`)),
+		)
+
+		baseURL, _ := url.Parse(testHost)
+		cl := client.NewWithHTTPClient(baseURL, testAPIKey, hc)
+
+		tmp := t.TempDir()
+		if err := os.MkdirAll(tmp+"/2024-05-12 Birthday/", 0o755); err != nil {
+			t.Fatalf("expected nil, got %v", err)
+		}
+
+		opts := &AutoCreateAlbumsOptions{
+			Folder: tmp + string(os.PathSeparator),
+			Dates:  &FolderDateOptions{Description: "January 2006"},
+		}
+
+		_, err := AutoCreateAlbums(ctx, cl, opts)
+		if err != nil {
+			t.Errorf("expected nil, got %v", err)
+		}
+	})
+
+	t.Run("success with normalized existing album", func(t *testing.T) {
+		ctx := context.Background()
+		hc := http.DefaultClient
+
+		httpmock.ActivateNonDefault(hc)
+		defer httpmock.DeactivateAndReset()
+
+		httpmock.RegisterResponder(
+			http.MethodGet,
+			testHost+"/api/albums",
+			httpmock.NewJsonResponderOrPanic(
+				http.StatusOK,
+				json.RawMessage(`[{"albumName": "Caf\u00e9", "id": "821256df-77e9-4616-91b9-57465995a01b"}]`),
+			),
+		)
+
+		httpmock.RegisterResponder(
+			http.MethodGet,
+			testHost+"/api/view/folder",
+			httpmock.NewJsonResponderOrPanic(
+				http.StatusOK,
+				json.RawMessage(`[{"id": "dff78948-b5b2-4d04-a493-ad65df879286"}]`),
+			),
+		)
+
+		httpmock.RegisterResponder(
+			http.MethodPut,
+			testHost+"/api/albums/821256df-77e9-4616-91b9-57465995a01b/assets",
+			httpmock.NewJsonResponderOrPanic(http.StatusOK, json.RawMessage(`[]`)),
+		)
+
+		baseURL, _ := url.Parse(testHost)
+		cl := client.NewWithHTTPClient(baseURL, testAPIKey, hc)
+
+		tmp := t.TempDir()
+		if err := os.MkdirAll(tmp+"/cafe\u0301/", 0o755); err != nil {
+			t.Fatalf("expected nil, got %v", err)
+		}
+
+		opts := &AutoCreateAlbumsOptions{
+			Folder:    tmp + string(os.PathSeparator),
+			Normalize: []string{NormalizeNFC, NormalizeCaseFold},
+		}
+
+		_, err := AutoCreateAlbums(ctx, cl, opts)
+		if err != nil {
+			t.Errorf("expected nil, got %v", err)
+		}
+	})
+
+	t.Run("success remote", func(t *testing.T) {
+		ctx := context.Background()
+		hc := http.DefaultClient
+
+		httpmock.ActivateNonDefault(hc)
+		defer httpmock.DeactivateAndReset()
+
+		httpmock.RegisterResponder(
+			http.MethodGet,
+			testHost+"/api/view/folder/unique-paths",
+			httpmock.NewJsonResponderOrPanic(
+				http.StatusOK,
+				json.RawMessage(`["/external/2025/food", "/external/2024/food"]`),
+			),
+		)
+
+		httpmock.RegisterResponder(
+			http.MethodGet,
+			testHost+"/api/albums",
+			httpmock.NewJsonResponderOrPanic(
+				http.StatusOK,
+				json.RawMessage(`[{"albumName": "food", "id": "821256df-77e9-4616-91b9-57465995a01b"}]`),
+			),
+		)
+
+		httpmock.RegisterResponderWithQuery(
+			http.MethodGet,
+			testHost+"/api/view/folder",
+			map[string]string{"path": "/external/2025/food"},
+			httpmock.NewJsonResponderOrPanic(
+				http.StatusOK,
+				json.RawMessage(`[{"id": "dff78948-b5b2-4d04-a493-ad65df879286"}]`),
+			),
+		)
+
+		httpmock.RegisterResponderWithQuery(
+			http.MethodGet,
+			testHost+"/api/view/folder",
+			map[string]string{"path": "/external/2025"},
+			httpmock.NewJsonResponderOrPanic(http.StatusOK, json.RawMessage(`[]`)),
+		)
+
+		httpmock.RegisterResponder(
+			http.MethodPost,
+			testHost+"/api/albums",
+			httpmock.NewJsonResponderOrPanic(http.StatusCreated, json.RawMessage(`{"albumName": "2025", "id": "a2025"}`)),
+		)
+
+		httpmock.RegisterResponder(
+			http.MethodPut,
+			testHost+"/api/albums/821256df-77e9-4616-91b9-57465995a01b/assets",
+			httpmock.NewJsonResponderOrPanic(http.StatusOK, json.RawMessage(`[]`)),
+		)
+
+		baseURL, _ := url.Parse(testHost)
+		cl := client.NewWithHTTPClient(baseURL, testAPIKey, hc)
+
+		opts := &AutoCreateAlbumsOptions{
+			Folder: "/external/2025/",
+			Remote: true,
+		}
+
+		_, err := AutoCreateAlbums(ctx, cl, opts)
+		if err != nil {
+			t.Errorf("expected nil, got %v", err)
+		}
+	})
+
+	t.Run("partly failed assets", func(t *testing.T) {
+		ctx := context.Background()
+		hc := http.DefaultClient
+
+		httpmock.ActivateNonDefault(hc)
+		defer httpmock.DeactivateAndReset()
+
+		httpmock.RegisterResponder(
+			http.MethodGet,
+			testHost+"/api/view/folder/unique-paths",
+			httpmock.NewJsonResponderOrPanic(http.StatusOK, json.RawMessage(`["/external/2025/food"]`)),
+		)
+
+		httpmock.RegisterResponder(
+			http.MethodGet,
+			testHost+"/api/albums",
+			httpmock.NewJsonResponderOrPanic(
+				http.StatusOK,
+				json.RawMessage(`[{"albumName": "food", "id": "821256df-77e9-4616-91b9-57465995a01b"}]`),
+			),
+		)
+
+		httpmock.RegisterResponderWithQuery(
+			http.MethodGet,
+			testHost+"/api/view/folder",
+			map[string]string{"path": "/external/2025/food"},
+			httpmock.NewJsonResponderOrPanic(
+				http.StatusOK,
+				json.RawMessage(`[{"id": "a1"}, {"id": "a2"}, {"id": "a3"}]`),
+			),
+		)
+
+		httpmock.RegisterResponderWithQuery(
+			http.MethodGet,
+			testHost+"/api/view/folder",
+			map[string]string{"path": "/external/2025"},
+			httpmock.NewJsonResponderOrPanic(http.StatusOK, json.RawMessage(`[]`)),
+		)
+
+		httpmock.RegisterResponder(
+			http.MethodPost,
+			testHost+"/api/albums",
+			httpmock.NewJsonResponderOrPanic(http.StatusCreated, json.RawMessage(`{"albumName": "2025", "id": "a2025"}`)),
+		)
+
+		httpmock.RegisterResponder(
+			http.MethodPut,
+			testHost+"/api/albums/821256df-77e9-4616-91b9-57465995a01b/assets",
+			httpmock.NewJsonResponderOrPanic(
+				http.StatusOK,
+				json.RawMessage(`[
+					{"id": "a1", "success": true},
+					{"id": "a2", "success": false, "error": "duplicate"},
+					{"id": "a3", "success": false, "error": "no_permission"}
+				]`),
+			),
+		)
+
+		baseURL, _ := url.Parse(testHost)
+		cl := client.NewWithHTTPClient(baseURL, testAPIKey, hc)
+
+		opts := &AutoCreateAlbumsOptions{
+			Folder: "/external/2025/",
+			Remote: true,
+		}
+
+		report, err := AutoCreateAlbums(ctx, cl, opts)
+		if err == nil || !strings.Contains(err.Error(), `asset "a3": no_permission`) {
+			t.Errorf("unexpected error: %v (expected asset \"a3\")", err)
+		}
+
+		if report.Assets != 1 {
+			t.Errorf("unexpected assets: %d (expected %d)", report.Assets, 1)
+		}
+	})
+
+	t.Run("invalid media type", func(t *testing.T) {
+		opts := &AutoCreateAlbumsOptions{
+			Folder: t.TempDir(),
+			Media:  &MediaFilter{Type: "audio"},
+		}
+
+		_, err := AutoCreateAlbums(context.Background(), nil, opts)
+		if err == nil || !strings.Contains(err.Error(), "invalid media type") {
+			t.Errorf("unexpected error: %v (expected invalid media type)", err)
+		}
+	})
+
+	t.Run("success with asset filters", func(t *testing.T) {
+		ctx := context.Background()
+		hc := http.DefaultClient
+
+		httpmock.ActivateNonDefault(hc)
+		defer httpmock.DeactivateAndReset()
+
+		httpmock.RegisterResponder(
+			http.MethodGet,
+			testHost+"/api/view/folder/unique-paths",
+			httpmock.NewJsonResponderOrPanic(
+				http.StatusOK,
+				json.RawMessage(`["/external/2025/food", "/external/2025/trip", "/external/2025/misc"]`),
+			),
+		)
+
+		httpmock.RegisterResponder(
+			http.MethodGet,
+			testHost+"/api/albums",
+			httpmock.NewJsonResponderOrPanic(http.StatusOK, json.RawMessage(`[]`)),
+		)
+
+		httpmock.RegisterResponderWithQuery(
+			http.MethodGet,
+			testHost+"/api/view/folder",
+			map[string]string{"path": "/external/2025/food"},
+			httpmock.NewJsonResponderOrPanic(
+				http.StatusOK,
+				json.RawMessage(`[
+					{"id": "1", "type": "IMAGE", "originalFileName": "a.jpg"},
+					{"id": "2", "type": "IMAGE", "originalFileName": "b.JPG"},
+					{"id": "3", "type": "VIDEO", "originalFileName": "c.mp4"}
+				]`),
+			),
+		)
+
+		httpmock.RegisterResponderWithQuery(
+			http.MethodGet,
+			testHost+"/api/view/folder",
+			map[string]string{"path": "/external/2025/trip"},
+			httpmock.NewJsonResponderOrPanic(
+				http.StatusOK,
+				json.RawMessage(`[
+					{"id": "4", "type": "IMAGE", "originalFileName": "a.png"},
+					{"id": "5", "type": "VIDEO", "originalFileName": "b.mov"}
+				]`),
+			),
+		)
+
+		httpmock.RegisterResponder(
+			http.MethodPost,
+			testHost+"/api/albums",
+			httpmock.NewJsonResponderOrPanic(
+				http.StatusOK,
+				json.RawMessage(`{"albumName": "food", "id": "4cbd308b-ed70-4fe9-92f3-ad4ac3ee8710"}`),
+			),
+		)
+
+		var added []string
+
+		httpmock.RegisterResponder(
+			http.MethodPut,
+			testHost+"/api/albums/4cbd308b-ed70-4fe9-92f3-ad4ac3ee8710/assets",
+			func(req *http.Request) (*http.Response, error) {
+				var body struct {
+					IDs []string `json:"ids"`
+				}
+
+				if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+					return nil, err
+				}
+
+				added = body.IDs
+
+				return httpmock.NewStringResponse(http.StatusOK, `[]`), nil
+			},
+		)
+
+		baseURL, _ := url.Parse(testHost)
+		cl := client.NewWithHTTPClient(baseURL, testAPIKey, hc)
+
+		opts := &AutoCreateAlbumsOptions{
+			Folder:    "/external/2025/",
+			Remote:    true,
+			Include:   []string{"food", "trip"},
+			MinAssets: 2,
+			Media:     &MediaFilter{Type: MediaImage, Extensions: []string{"jpg"}},
+		}
+
+		report, err := AutoCreateAlbums(ctx, cl, opts)
+		if err != nil {
+			t.Errorf("expected nil, got %v", err)
+		}
+
+		if expected := []string{"1", "2"}; !reflect.DeepEqual(added, expected) {
+			t.Errorf("unexpected assets added: %v (expected %v)", added, expected)
+		}
+
+		expected := []SkippedFolder{{Folder: "/external/2025/trip", Assets: 0}}
+		if !reflect.DeepEqual(report.Skipped, expected) {
+			t.Errorf("unexpected skipped folders: %v (expected %v)", report.Skipped, expected)
+		}
+	})
 }
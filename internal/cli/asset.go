@@ -10,6 +10,7 @@ import (
 	"net/http"
 	"net/url"
 	"sync"
+	"time"
 
 	"golang.org/x/sync/errgroup"
 
@@ -17,50 +18,60 @@ import (
 	"github.com/faabiosr/imt/internal/errors"
 )
 
-func fetchAssetsIDsByOriginalPath(ctx context.Context, cl *client.Client, path string) ([]string, error) {
+// Asset represents an asset stored in Immich.
+type Asset struct {
+	ID               string    `json:"id"`
+	Type             string    `json:"type"`
+	OriginalPath     string    `json:"originalPath"`
+	OriginalFileName string    `json:"originalFileName"`
+	LocalDateTime    time.Time `json:"localDateTime,omitzero"`
+	IsFavorite       bool      `json:"isFavorite,omitempty"`
+	IsTrashed        bool      `json:"isTrashed,omitempty"`
+}
+
+// assetIDs returns the IDs of the assets.
+func assetIDs(assets []Asset) []string {
+	ids := make([]string, 0, len(assets))
+	for _, a := range assets {
+		ids = append(ids, a.ID)
+	}
+
+	return ids
+}
+
+func fetchAssetsByOriginalPath(ctx context.Context, cl *client.Client, path string) ([]Asset, error) {
 	resource, _ := url.Parse("/api/view/folder")
 	query := resource.Query()
 	query.Add("path", path)
 
 	resource.RawQuery = query.Encode()
 
-	ids := []string{}
+	assets := []Asset{}
 
 	req, err := cl.NewRequest(ctx, http.MethodGet, resource, nil)
 	if err != nil {
-		return ids, err
-	}
-
-	res := []struct {
-		ID string `json:"id"`
-	}{}
-
-	if err := cl.Do(req, &res); err != nil {
-		return ids, err
-	}
-
-	for _, asset := range res {
-		ids = append(ids, asset.ID)
+		return assets, err
 	}
 
-	return ids, nil
+	return assets, cl.Do(req, &assets)
 }
 
-func fetchAssetsIDsByOriginalPaths(ctx context.Context, cl *client.Client, paths []string) ([]string, error) {
-	assets := []string{}
+// fetchAssetsByOriginalPaths returns the assets of every path, concurrently.
+func fetchAssetsByOriginalPaths(ctx context.Context, cl *client.Client, paths []string) (map[string][]Asset, error) {
+	assets := make(map[string][]Asset, len(paths))
 	m := sync.Mutex{}
 
 	g, ctx := errgroup.WithContext(ctx)
 
 	fn := func(path string) func() error {
 		return func() error {
-			ids, err := fetchAssetsIDsByOriginalPath(ctx, cl, path)
+			res, err := fetchAssetsByOriginalPath(ctx, cl, path)
 			if err != nil {
 				return err
 			}
 
 			m.Lock()
-			assets = append(assets, ids...)
+			assets[path] = res
 			m.Unlock()
 
 			return nil
@@ -72,7 +83,7 @@ func fetchAssetsIDsByOriginalPaths(ctx context.Context, cl *client.Client, paths
 	}
 
 	if err := g.Wait(); err != nil {
-		return []string{}, errors.Errorf("one of the paths failed to retrieve assets: %w", err)
+		return map[string][]Asset{}, errors.Errorf("one of the paths failed to retrieve assets: %w", err)
 	}
 
 	return assets, nil
@@ -40,7 +40,7 @@ func TestAsset_fetchAssetByOriginalPath(t *testing.T) {
 
 		cl := client.NewWithHTTPClient(baseURL, testAPIKey, hc)
 
-		_, err := fetchAssetsIDsByOriginalPath(ctx, cl, path)
+		_, err := fetchAssetsByOriginalPath(ctx, cl, path)
 		if err == nil {
 			t.Error("expected an error, got nil")
 		}
@@ -68,18 +68,18 @@ func TestAsset_fetchAssetByOriginalPath(t *testing.T) {
 
 		cl := client.NewWithHTTPClient(baseURL, testAPIKey, hc)
 
-		ids, err := fetchAssetsIDsByOriginalPath(ctx, cl, path)
+		assets, err := fetchAssetsByOriginalPath(ctx, cl, path)
 		if err != nil {
 			t.Error(err)
 		}
 
-		if n := len(ids); n != 2 {
-			t.Errorf("unexpected number of assets ids: %d (expected %d)", n, 2)
+		if n := len(assets); n != 2 {
+			t.Errorf("unexpected number of assets: %d (expected %d)", n, 2)
 		}
 	})
 }
 
-func TestAsset_fetchAssetsIDsByOriginalPaths(t *testing.T) {
+func TestAsset_fetchAssetsByOriginalPaths(t *testing.T) {
 	t.Run("failure", func(t *testing.T) {
 		ctx := context.Background()
 		path1 := "/media/cars"
@@ -114,7 +114,7 @@ func TestAsset_fetchAssetsIDsByOriginalPaths(t *testing.T) {
 
 		cl := client.NewWithHTTPClient(baseURL, testAPIKey, hc)
 
-		_, err := fetchAssetsIDsByOriginalPaths(ctx, cl, paths)
+		_, err := fetchAssetsByOriginalPaths(ctx, cl, paths)
 		if err == nil {
 			t.Error("expected an error, got nil")
 		}
@@ -154,13 +154,13 @@ func TestAsset_fetchAssetsIDsByOriginalPaths(t *testing.T) {
 
 		cl := client.NewWithHTTPClient(baseURL, testAPIKey, hc)
 
-		ids, err := fetchAssetsIDsByOriginalPaths(ctx, cl, paths)
+		assets, err := fetchAssetsByOriginalPaths(ctx, cl, paths)
 		if err != nil {
 			t.Errorf("expected nil, got %v", err)
 		}
 
-		if n := len(ids); n != 2 {
-			t.Errorf("unexpected number of assets ids: %d (expected %d)", n, 2)
+		if n := len(assets[path1]) + len(assets[path2]); n != 2 {
+			t.Errorf("unexpected number of assets: %d (expected %d)", n, 2)
 		}
 	})
 }
@@ -0,0 +1,602 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package cli
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+
+	"github.com/faabiosr/imt/internal/client"
+	"github.com/faabiosr/imt/internal/errors"
+)
+
+// AutoCreateJob represents a named set of options to auto create albums.
+type AutoCreateJob struct {
+	Name    string
+	Options *AutoCreateAlbumsOptions
+}
+
+// AutoCreateJobReport holds the outcome of an auto create job.
+type AutoCreateJobReport struct {
+	Job    string
+	Report *AutoCreateAlbumsReport
+	Err    error
+}
+
+// LoadAutoCreateJobs reads the auto create jobs from a JSON, YAML or TOML
+// config file, the format is chosen by the file extension and files with
+// other extensions are read as JSON.
+//
+// The config holds a list of named jobs and the defaults shared by all of
+// them, the options set by a job replace the defaults. A config without jobs
+// holds the options of a single unnamed job. Unknown fields are not allowed.
+func LoadAutoCreateJobs(name string) ([]AutoCreateJob, error) {
+	f, err := readConfigFile(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return checkedJobs(f)
+}
+
+// ConfigIssue represents a problem found in a config file, located by the
+// path of the value, like "jobs[1].exclude[0]", and its line and column.
+type ConfigIssue struct {
+	Path    string
+	Line    int
+	Column  int
+	Message string
+}
+
+// Error returns the issue description with its location.
+func (i ConfigIssue) Error() string {
+	var b strings.Builder
+
+	if i.Line > 0 {
+		_, _ = fmt.Fprintf(&b, "line %d, column %d: ", i.Line, i.Column)
+	}
+
+	if i.Path != "" {
+		_, _ = b.WriteString(i.Path + ": ")
+	}
+
+	_, _ = b.WriteString(i.Message)
+
+	return b.String()
+}
+
+// configFile holds the decoded values of a config file and their positions.
+type configFile struct {
+	doc map[string]any
+	pos configPositions
+}
+
+// readConfigFile reads and decodes the config file.
+func readConfigFile(name string) (*configFile, error) {
+	if name == "" {
+		return nil, errors.New("empty filename is not allowed")
+	}
+
+	content, err := os.ReadFile(filepath.Clean(name))
+	if err != nil {
+		return nil, errors.Errorf("unable to read config file: %w", err)
+	}
+
+	return decodeConfigFile(content, filepath.Ext(name))
+}
+
+// decodeConfigFile decodes the config in the format of the extension, JSON
+// for unknown extensions. Syntax errors are returned as a config issue.
+func decodeConfigFile(content []byte, ext string) (*configFile, error) {
+	var (
+		doc map[string]any
+		err error
+	)
+
+	ext = strings.ToLower(ext)
+
+	switch ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &doc)
+	case ".toml":
+		err = toml.Unmarshal(content, &doc)
+	default:
+		ext = ".json"
+		err = json.Unmarshal(content, &doc)
+	}
+
+	if err != nil {
+		return nil, syntaxIssue(content, err)
+	}
+
+	if doc == nil {
+		doc = map[string]any{}
+	}
+
+	doc, _ = configMap(doc)
+
+	return &configFile{doc: doc, pos: indexPositions(content, ext)}, nil
+}
+
+// yamlErrorLine matches the line reported by YAML errors.
+var yamlErrorLine = regexp.MustCompile(`line (\d+)`)
+
+// syntaxIssue locates the decoding error.
+func syntaxIssue(content []byte, err error) ConfigIssue {
+	issue := ConfigIssue{Message: fmt.Sprintf("unable to decode: %v", err)}
+
+	var (
+		syntaxErr *json.SyntaxError
+		typeErr   *json.UnmarshalTypeError
+		tomlErr   *toml.DecodeError
+	)
+
+	switch {
+	case errors.As(err, &syntaxErr):
+		// the offset is after the invalid character.
+		pos := offsetPosition(content, int(syntaxErr.Offset)-1)
+		issue.Line, issue.Column = pos.line, pos.column
+	case errors.As(err, &typeErr):
+		pos := offsetPosition(content, int(typeErr.Offset))
+		issue.Line, issue.Column = pos.line, pos.column
+	case errors.As(err, &tomlErr):
+		issue.Line, issue.Column = tomlErr.Position()
+	default:
+		if m := yamlErrorLine.FindStringSubmatch(err.Error()); m != nil {
+			issue.Line, issue.Column = atoi(m[1]), 1
+		}
+	}
+
+	return issue
+}
+
+// hasJobs reports whether the config holds a list of jobs.
+func (f *configFile) hasJobs() bool {
+	_, ok := f.doc["jobs"]
+	return ok
+}
+
+// check reports the unknown fields and mismatched types of the config.
+func (f *configFile) check() []ConfigIssue {
+	options := reflect.TypeFor[AutoCreateAlbumsOptions]()
+
+	if !f.hasJobs() {
+		return f.locate(checkValue(f.doc, options, ""))
+	}
+
+	var issues []ConfigIssue
+
+	for _, k := range slices.Sorted(maps.Keys(f.doc)) {
+		if k != "defaults" && k != "jobs" {
+			issues = append(issues, ConfigIssue{
+				Path:    k,
+				Message: fmt.Sprintf("unknown field %q, options must be set in defaults or jobs", k),
+			})
+		}
+	}
+
+	if v, ok := f.doc["defaults"]; ok {
+		if _, ok := v.(map[string]any); !ok {
+			issues = append(issues, ConfigIssue{Path: "defaults", Message: "must be an object"})
+		} else {
+			issues = append(issues, checkValue(v, options, "defaults")...)
+		}
+	}
+
+	items, ok := f.doc["jobs"].([]any)
+	if !ok || len(items) == 0 {
+		issues = append(issues, ConfigIssue{Path: "jobs", Message: "must be a non-empty list"})
+		return f.locate(issues)
+	}
+
+	names := map[string]bool{}
+
+	for i, item := range items {
+		path := fmt.Sprintf("jobs[%d]", i)
+
+		values, ok := item.(map[string]any)
+		if !ok {
+			issues = append(issues, ConfigIssue{Path: path, Message: "must be an object"})
+			continue
+		}
+
+		name := jobName(values)
+
+		switch {
+		case name == "":
+			issues = append(issues, ConfigIssue{Path: path, Message: "name is required"})
+		case names[name]:
+			issues = append(issues, ConfigIssue{Path: path + ".name", Message: fmt.Sprintf("duplicate job name %q", name)})
+		}
+
+		names[name] = true
+
+		values = maps.Clone(values)
+		delete(values, "name")
+
+		issues = append(issues, checkValue(values, options, path)...)
+	}
+
+	return f.locate(issues)
+}
+
+// locate sets the line and column of the issues, ordering them by position.
+func (f *configFile) locate(issues []ConfigIssue) []ConfigIssue {
+	for i := range issues {
+		pos := f.pos.lookup(issues[i].Path)
+		issues[i].Line, issues[i].Column = pos.line, pos.column
+	}
+
+	slices.SortStableFunc(issues, func(a, b ConfigIssue) int {
+		return cmp.Or(cmp.Compare(a.Line, b.Line), cmp.Compare(a.Column, b.Column))
+	})
+
+	return issues
+}
+
+// jobs returns the jobs of the config, which must be checked before.
+func (f *configFile) jobs() ([]AutoCreateJob, error) {
+	if !f.hasJobs() {
+		opts, err := decodeAutoCreateOptions(f.doc)
+		if err != nil {
+			return nil, err
+		}
+
+		return []AutoCreateJob{{Options: opts}}, nil
+	}
+
+	all := f.jobValues()
+	jobs := make([]AutoCreateJob, 0, len(all))
+
+	for _, values := range all {
+		name := jobName(values)
+		delete(values, "name")
+
+		opts, err := decodeAutoCreateOptions(values)
+		if err != nil {
+			return nil, errors.Errorf("invalid config job %q: %w", name, err)
+		}
+
+		jobs = append(jobs, AutoCreateJob{Name: name, Options: opts})
+	}
+
+	return jobs, nil
+}
+
+// jobValues returns the values of every job merged with the defaults, nil for
+// the jobs that are not objects. A config without jobs holds the values of a
+// single job.
+func (f *configFile) jobValues() []map[string]any {
+	if !f.hasJobs() {
+		return []map[string]any{f.doc}
+	}
+
+	defaults, _ := f.doc["defaults"].(map[string]any)
+	items, _ := f.doc["jobs"].([]any)
+
+	all := make([]map[string]any, 0, len(items))
+
+	for _, item := range items {
+		values, ok := item.(map[string]any)
+		if !ok {
+			all = append(all, nil)
+			continue
+		}
+
+		merged := maps.Clone(defaults)
+		if merged == nil {
+			merged = map[string]any{}
+		}
+
+		maps.Copy(merged, values)
+		all = append(all, merged)
+	}
+
+	return all
+}
+
+// jobPath returns the config path of an option of the job at index i, which
+// is in the defaults when not set by the job.
+func (f *configFile) jobPath(i int, rel string) string {
+	if !f.hasJobs() {
+		return rel
+	}
+
+	items, _ := f.doc["jobs"].([]any)
+	values, _ := items[i].(map[string]any)
+	defaults, _ := f.doc["defaults"].(map[string]any)
+
+	field, _, _ := strings.Cut(strings.SplitN(rel, "[", 2)[0], ".")
+
+	_, inJob := values[field]
+	_, inDefaults := defaults[field]
+
+	if field != "" && !inJob && inDefaults {
+		return joinPath("defaults", rel)
+	}
+
+	return joinPath(fmt.Sprintf("jobs[%d]", i), rel)
+}
+
+// jobName returns the name of the job, YAML names may be numbers like years.
+func jobName(values map[string]any) string {
+	v, ok := values["name"]
+	if !ok || v == nil {
+		return ""
+	}
+
+	return fmt.Sprint(v)
+}
+
+// checkedJobs returns the jobs of the config file when it has no issues.
+func checkedJobs(f *configFile) ([]AutoCreateJob, error) {
+	issues := f.check()
+	if len(issues) == 0 {
+		return f.jobs()
+	}
+
+	errs := make([]error, 0, len(issues))
+	for _, issue := range issues {
+		errs = append(errs, issue)
+	}
+
+	return nil, errors.Errorf("invalid auto create albums config file:\n%w", errors.Join(errs...))
+}
+
+// ValidateAutoCreateConfig checks the config file without any server calls,
+// reporting syntax errors, unknown fields, mismatched types, invalid
+// patterns and non-existent folders.
+func ValidateAutoCreateConfig(name string) ([]ConfigIssue, error) {
+	f, err := readConfigFile(name)
+
+	var issue ConfigIssue
+	if errors.As(err, &issue) {
+		return []ConfigIssue{issue}, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	issues := f.check()
+
+	for i, values := range f.jobValues() {
+		if values == nil {
+			continue
+		}
+
+		// the values with issues are left out, so that the options are
+		// checked in the same pass.
+		values = maps.Clone(values)
+		delete(values, "name")
+
+		for k := range values {
+			if hasConfigIssue(issues, f.jobPath(i, k)) {
+				delete(values, k)
+			}
+		}
+
+		opts, err := decodeAutoCreateOptions(values)
+		if err != nil {
+			continue
+		}
+
+		for _, issue := range validateAutoCreateOptions(opts) {
+			// the defaults are reported once, not for every job.
+			issue.Path = f.jobPath(i, issue.Path)
+			if !slices.Contains(issues, issue) {
+				issues = append(issues, issue)
+			}
+		}
+	}
+
+	return f.locate(issues), nil
+}
+
+// hasConfigIssue checks if there are issues of the value at the path or of
+// the values inside it.
+func hasConfigIssue(issues []ConfigIssue, path string) bool {
+	return slices.ContainsFunc(issues, func(i ConfigIssue) bool {
+		rest, ok := strings.CutPrefix(i.Path, path)
+		return ok && (rest == "" || rest[0] == '.' || rest[0] == '[')
+	})
+}
+
+// validateAutoCreateOptions checks the option values, the paths of the issues
+// are relative to the options.
+func validateAutoCreateOptions(opts *AutoCreateAlbumsOptions) []ConfigIssue {
+	var issues []ConfigIssue
+
+	add := func(path string, err error) {
+		if err != nil {
+			issues = append(issues, ConfigIssue{Path: path, Message: err.Error()})
+		}
+	}
+
+	switch {
+	case opts.Folder == "":
+		add("folder", errors.New("folder is required"))
+	case !opts.Remote:
+		add("folder", checkFolder(filepath.Dir(opts.Folder)))
+	}
+
+	if opts.SkipLevels < 0 {
+		add("skip_levels", errors.New("must not be negative"))
+	}
+
+	if opts.MinAssets < 0 {
+		add("min_assets", errors.New("must not be negative"))
+	}
+
+	if opts.Folder != "" {
+		_, err := newPathMapper(opts)
+		add("path_mappings", err)
+	}
+
+	for i, p := range opts.Exclude {
+		_, err := newExcludeRule(p, opts.excludeGlobs(), false)
+		add(fmt.Sprintf("exclude[%d]", i), err)
+	}
+
+	for i, p := range opts.Include {
+		_, err := newExcludeRule(p, globOptions{legacy: opts.LegacyGlobs}, false)
+		add(fmt.Sprintf("include[%d]", i), err)
+	}
+
+	if opts.NameTemplate != "" {
+		_, err := template.New("name").Funcs(nameFuncs).Parse(opts.NameTemplate)
+		add("name_template", err)
+	}
+
+	for i, r := range opts.RenameRules {
+		_, err := regexp.Compile(r.Pattern)
+		add(fmt.Sprintf("rename_rules[%d].pattern", i), err)
+	}
+
+	if opts.Dates != nil {
+		add("dates", opts.Dates.validate())
+	}
+
+	add("on_conflict", validateConflictPolicy(opts.OnConflict))
+
+	_, err := newNormalizer(opts.Normalize)
+	add("normalize", err)
+
+	add("media.type", opts.Media.validate())
+
+	_, err = parseAlbumShares(opts.Share)
+	add("share", err)
+
+	return issues
+}
+
+// checkFolder checks the folder exists.
+func checkFolder(folder string) error {
+	info, err := os.Stat(folder)
+	if errors.Is(err, os.ErrNotExist) {
+		return errors.Errorf("folder %q does not exist", folder)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if !info.IsDir() {
+		return errors.Errorf("%q is not a folder", folder)
+	}
+
+	return nil
+}
+
+// decodeAutoCreateOptions decodes the options from the config values, using
+// the same field names whatever the config format is.
+func decodeAutoCreateOptions(values map[string]any) (*AutoCreateAlbumsOptions, error) {
+	content, err := json.Marshal(configValue(values))
+	if err != nil {
+		return nil, errors.Errorf("unable to decode auto create albums options: %w", err)
+	}
+
+	var opts AutoCreateAlbumsOptions
+	if err := json.Unmarshal(content, &opts); err != nil {
+		return nil, errors.Errorf("unable to decode auto create albums options: %w", err)
+	}
+
+	return &opts, nil
+}
+
+// configMap returns the value as an object, YAML objects may have non string
+// keys like years.
+func configMap(v any) (map[string]any, bool) {
+	m, ok := configValue(v).(map[string]any)
+	return m, ok
+}
+
+// configValue converts the objects with non string keys into objects with
+// string keys, so they can be encoded as JSON.
+func configValue(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		m := make(map[string]any, len(t))
+		for k, val := range t {
+			m[k] = configValue(val)
+		}
+
+		return m
+	case map[any]any:
+		m := make(map[string]any, len(t))
+		for k, val := range t {
+			m[fmt.Sprint(k)] = configValue(val)
+		}
+
+		return m
+	case []any:
+		s := make([]any, 0, len(t))
+		for _, val := range t {
+			s = append(s, configValue(val))
+		}
+
+		return s
+	default:
+		return v
+	}
+}
+
+// SelectAutoCreateJobs returns the jobs named, or all the jobs when no names
+// are given.
+func SelectAutoCreateJobs(jobs []AutoCreateJob, names []string) ([]AutoCreateJob, error) {
+	if len(names) == 0 {
+		return jobs, nil
+	}
+
+	selected := make([]AutoCreateJob, 0, len(names))
+
+	for _, name := range names {
+		i := slices.IndexFunc(jobs, func(j AutoCreateJob) bool { return j.Name == name })
+		if i < 0 {
+			return nil, errors.Errorf("config job %q not found", name)
+		}
+
+		selected = append(selected, jobs[i])
+	}
+
+	return selected, nil
+}
+
+// RunAutoCreateJobs runs the jobs sequentially, a failing job does not stop
+// the next ones. The error returned joins the errors of all jobs.
+func RunAutoCreateJobs(ctx context.Context, cl *client.Client, jobs []AutoCreateJob) ([]AutoCreateJobReport, error) {
+	reports := make([]AutoCreateJobReport, 0, len(jobs))
+
+	var errs []error
+
+	for _, job := range jobs {
+		if err := ctx.Err(); err != nil {
+			return reports, err
+		}
+
+		report, err := AutoCreateAlbums(ctx, cl, job.Options)
+		if err != nil {
+			errs = append(errs, errors.Errorf("job %q: %w", job.Name, err))
+		}
+
+		reports = append(reports, AutoCreateJobReport{Job: job.Name, Report: report, Err: err})
+	}
+
+	return reports, errors.Join(errs...)
+}
@@ -0,0 +1,469 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+
+	"github.com/faabiosr/imt/internal/client"
+)
+
+func TestConfig_LoadAutoCreateJobs(t *testing.T) {
+	t.Run("empty filename", func(t *testing.T) {
+		if _, err := LoadAutoCreateJobs(""); err == nil {
+			t.Error("expected an error, got nil")
+		}
+	})
+
+	t.Run("file not found", func(t *testing.T) {
+		if _, err := LoadAutoCreateJobs(filepath.Join(t.TempDir(), "jobs.yaml")); err == nil {
+			t.Error("expected an error, got nil")
+		}
+	})
+
+	t.Run("examples", func(t *testing.T) {
+		for _, name := range []string{"example_auto_create.json", "example_auto_create_jobs.yaml"} {
+			jobs, err := LoadAutoCreateJobs(filepath.Join("..", "..", name))
+			if err != nil {
+				t.Errorf("expected nil, got %v", err)
+			}
+
+			if len(jobs) == 0 || jobs[0].Options.Folder == "" {
+				t.Errorf("unexpected jobs of %s: %v", name, jobs)
+			}
+		}
+	})
+
+	t.Run("single job", func(t *testing.T) {
+		name := filepath.Join(t.TempDir(), "config.json")
+		if err := os.WriteFile(name, []byte(`{"folder": "/photos/", "recursive": true}`), 0o600); err != nil {
+			t.Fatalf("expected nil, got %v", err)
+		}
+
+		jobs, err := LoadAutoCreateJobs(name)
+		if err != nil {
+			t.Errorf("expected nil, got %v", err)
+		}
+
+		expected := []AutoCreateJob{{Options: &AutoCreateAlbumsOptions{Folder: "/photos/", Recursive: true}}}
+		if !reflect.DeepEqual(jobs, expected) {
+			t.Errorf("unexpected jobs: %v (expected %v)", jobs, expected)
+		}
+	})
+}
+
+// writeConfigFile writes the config content into a file with the extension.
+func writeConfigFile(t *testing.T, ext, content string) string {
+	t.Helper()
+
+	name := filepath.Join(t.TempDir(), "config"+ext)
+	if err := os.WriteFile(name, []byte(content), 0o600); err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+
+	return name
+}
+
+func TestConfig_LoadAutoCreateJobsFormats(t *testing.T) {
+	expected := []AutoCreateJob{
+		{
+			Name: "2024",
+			Options: &AutoCreateAlbumsOptions{
+				Folder:    "/photos/2024/",
+				Recursive: true,
+				Exclude:   []string{"Raw"},
+				Albums:    map[string]string{"2024": "Year"},
+			},
+		},
+		{
+			Name: "2025",
+			Options: &AutoCreateAlbumsOptions{
+				Folder:     "/photos/2025/",
+				Recursive:  false,
+				SkipLevels: 1,
+				Exclude:    []string{"Raw"},
+			},
+		},
+	}
+
+	configs := map[string]string{
+		".yaml": `
+defaults:
+  recursive: true
+  exclude: [Raw]
+jobs:
+  - name: 2024
+    folder: /photos/2024/
+    albums:
+      2024: Year
+  - name: "2025"
+    folder: /photos/2025/
+    recursive: false
+    skip_levels: 1
+`,
+		".toml": `
+[defaults]
+recursive = true
+exclude = ["Raw"]
+
+[[jobs]]
+name = "2024"
+folder = "/photos/2024/"
+albums = { "2024" = "Year" }
+
+[[jobs]]
+name = "2025"
+folder = "/photos/2025/"
+recursive = false
+skip_levels = 1
+`,
+		".json": `{
+  "defaults": {"recursive": true, "exclude": ["Raw"]},
+  "jobs": [
+    {"name": "2024", "folder": "/photos/2024/", "albums": {"2024": "Year"}},
+    {"name": "2025", "folder": "/photos/2025/", "recursive": false, "skip_levels": 1}
+  ]
+}`,
+	}
+
+	// files without a known extension are read as JSON.
+	configs[""] = configs[".json"]
+	configs[".conf"] = configs[".json"]
+
+	for ext, content := range configs {
+		t.Run(ext, func(t *testing.T) {
+			jobs, err := LoadAutoCreateJobs(writeConfigFile(t, ext, content))
+			if err != nil {
+				t.Errorf("expected nil, got %v", err)
+			}
+
+			if !reflect.DeepEqual(jobs, expected) {
+				t.Errorf("unexpected jobs: %+v (expected %+v)", jobs, expected)
+			}
+		})
+	}
+
+	failures := []struct {
+		name    string
+		ext     string
+		content string
+		err     string
+	}{
+		{"unknown extension read as json", ".conf", "jobs: [", "unable to decode"},
+		{"invalid content", ".yaml", "jobs: [", "unable to decode"},
+		{"empty jobs", ".json", `{"jobs": []}`, "jobs: must be a non-empty list"},
+		{"invalid defaults", ".json", `{"defaults": [], "jobs": [{"name": "a"}]}`, "line 1, column 2: defaults: must be an object"},
+		{"invalid job", ".json", `{"jobs": ["a"]}`, "jobs[0]: must be an object"},
+		{"job without name", ".json", `{"jobs": [{"folder": "/a/"}]}`, "jobs[0]: name is required"},
+		{"duplicate job", ".json", `{"jobs": [{"name": "a"}, {"name": "a"}]}`, `line 1, column 27: jobs[1].name: duplicate job name "a"`},
+		{"invalid options", ".json", `{"jobs": [{"name": "a", "recursive": "yes"}]}`, "line 1, column 25: jobs[0].recursive: must be a boolean"},
+	}
+
+	for _, tt := range failures {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadAutoCreateJobs(writeConfigFile(t, tt.ext, tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("unexpected error: %v (expected %s)", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestConfig_SelectAutoCreateJobs(t *testing.T) {
+	jobs := []AutoCreateJob{{Name: "2024"}, {Name: "2025"}}
+
+	t.Run("all jobs", func(t *testing.T) {
+		got, err := SelectAutoCreateJobs(jobs, nil)
+		if err != nil {
+			t.Errorf("expected nil, got %v", err)
+		}
+
+		if !reflect.DeepEqual(got, jobs) {
+			t.Errorf("unexpected jobs: %v (expected %v)", got, jobs)
+		}
+	})
+
+	t.Run("named jobs", func(t *testing.T) {
+		got, err := SelectAutoCreateJobs(jobs, []string{"2025"})
+		if err != nil {
+			t.Errorf("expected nil, got %v", err)
+		}
+
+		if expected := jobs[1:]; !reflect.DeepEqual(got, expected) {
+			t.Errorf("unexpected jobs: %v (expected %v)", got, expected)
+		}
+	})
+
+	t.Run("job not found", func(t *testing.T) {
+		if _, err := SelectAutoCreateJobs(jobs, []string{"2023"}); err == nil {
+			t.Error("expected an error, got nil")
+		}
+	})
+}
+
+func TestConfig_RunAutoCreateJobs(t *testing.T) {
+	ctx := context.Background()
+	hc := http.DefaultClient
+
+	httpmock.ActivateNonDefault(hc)
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder(
+		http.MethodGet,
+		testHost+"/api/view/folder/unique-paths",
+		httpmock.NewJsonResponderOrPanic(http.StatusOK, json.RawMessage(`["/external/2025/food"]`)),
+	)
+
+	httpmock.RegisterResponder(
+		http.MethodGet,
+		testHost+"/api/albums",
+		httpmock.NewJsonResponderOrPanic(
+			http.StatusOK,
+			json.RawMessage(`[{"albumName": "food", "id": "821256df-77e9-4616-91b9-57465995a01b"}]`),
+		),
+	)
+
+	httpmock.RegisterResponderWithQuery(
+		http.MethodGet,
+		testHost+"/api/view/folder",
+		map[string]string{"path": "/external/2025/food"},
+		httpmock.NewJsonResponderOrPanic(
+			http.StatusOK,
+			json.RawMessage(`[{"id": "dff78948-b5b2-4d04-a493-ad65df879286"}]`),
+		),
+	)
+
+	httpmock.RegisterResponderWithQuery(
+		http.MethodGet,
+		testHost+"/api/view/folder",
+		map[string]string{"path": "/external/2025"},
+		httpmock.NewJsonResponderOrPanic(http.StatusOK, json.RawMessage(`[]`)),
+	)
+
+	httpmock.RegisterResponder(
+		http.MethodPost,
+		testHost+"/api/albums",
+		httpmock.NewJsonResponderOrPanic(http.StatusCreated, json.RawMessage(`{"albumName": "2025", "id": "a2025"}`)),
+	)
+
+	httpmock.RegisterResponder(
+		http.MethodPut,
+		testHost+"/api/albums/821256df-77e9-4616-91b9-57465995a01b/assets",
+		httpmock.NewJsonResponderOrPanic(
+			http.StatusOK,
+			json.RawMessage(`[{"id": "dff78948-b5b2-4d04-a493-ad65df879286", "success": true}]`),
+		),
+	)
+
+	baseURL, _ := url.Parse(testHost)
+	cl := client.NewWithHTTPClient(baseURL, testAPIKey, hc)
+
+	jobs := []AutoCreateJob{
+		{Name: "invalid", Options: &AutoCreateAlbumsOptions{Folder: "/external/2025/", Remote: true, OnConflict: "nope"}},
+		{Name: "2025", Options: &AutoCreateAlbumsOptions{Folder: "/external/2025/", Remote: true}},
+	}
+
+	reports, err := RunAutoCreateJobs(ctx, cl, jobs)
+	if err == nil || !strings.Contains(err.Error(), `job "invalid"`) {
+		t.Errorf("unexpected error: %v (expected job \"invalid\")", err)
+	}
+
+	if n := len(reports); n != 2 {
+		t.Fatalf("unexpected number of reports: %d (expected %d)", n, 2)
+	}
+
+	if reports[0].Err == nil {
+		t.Error("expected an error, got nil")
+	}
+
+	if r := reports[1]; r.Job != "2025" || r.Err != nil || r.Report.Assets != 1 {
+		t.Errorf("unexpected report: %+v", r)
+	}
+}
+
+func TestConfig_ValidateAutoCreateConfig(t *testing.T) {
+	tmp := t.TempDir()
+	photos := filepath.Join(tmp, "photos") + string(os.PathSeparator)
+
+	if err := os.MkdirAll(photos, 0o755); err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+
+	t.Run("file not found", func(t *testing.T) {
+		if _, err := ValidateAutoCreateConfig(filepath.Join(tmp, "none.json")); err == nil {
+			t.Error("expected an error, got nil")
+		}
+	})
+
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		want    []ConfigIssue
+	}{
+		{
+			name:    "valid",
+			file:    "valid.json",
+			content: `{"folder": "` + photos + `", "exclude": ["i:raw"]}`,
+		},
+		{
+			name:    "json syntax error",
+			file:    "syntax.json",
+			content: "{\n  \"recursive\": tru\n}",
+			want:    []ConfigIssue{{Line: 2, Column: 19}},
+		},
+		{
+			name:    "yaml syntax error",
+			file:    "syntax.yaml",
+			content: "folder: /photos/\n  recursive: [\n",
+			want:    []ConfigIssue{{Line: 2, Column: 1}},
+		},
+		{
+			name:    "toml syntax error",
+			file:    "syntax.toml",
+			content: "folder = \"/photos/\"\nrecursive = \n",
+			want:    []ConfigIssue{{Line: 2, Column: 13}},
+		},
+		{
+			name:    "json unknown field",
+			file:    "unknown.json",
+			content: "{\n  \"folder\": \"" + photos + "\",\n  \"skiplevels\": 2\n}",
+			want: []ConfigIssue{{
+				Path:    "skiplevels",
+				Line:    3,
+				Column:  3,
+				Message: `unknown field "skiplevels", did you mean "skip_levels"?`,
+			}},
+		},
+		{
+			name: "yaml jobs",
+			file: "jobs.yaml",
+			content: `defaults:
+  recursive: true
+  exclude: ["***"]
+jobs:
+  - name: 2024
+    folder: /missing/2024/
+  - name: 2025
+    folder: ` + photos + `
+    rename_rules:
+      - pattern: "("
+        replacement: ""
+`,
+			want: []ConfigIssue{
+				{Path: "defaults.exclude[0]", Line: 3, Column: 13, Message: `too many stars in "***"`},
+				{Path: "jobs[0].folder", Line: 6, Column: 5, Message: `folder "/missing/2024" does not exist`},
+				{Path: "jobs[1].rename_rules[0].pattern", Line: 10, Column: 9, Message: "error parsing regexp: missing closing ): `(`"},
+			},
+		},
+		{
+			name: "toml unknown field",
+			file: "jobs.toml",
+			content: `[defaults]
+remote = true
+
+[[jobs]]
+name = "a"
+folder = "/external/a/"
+
+[[jobs]]
+name = "b"
+folder = "/external/b/"
+path_mappings = [{ local = "/mnt", sever = "/external" }]
+`,
+			want: []ConfigIssue{
+				{Path: "jobs[1].path_mappings[0].sever", Line: 11, Column: 36, Message: `unknown field "sever", did you mean "server"?`},
+			},
+		},
+		{
+			name: "toml values",
+			file: "values.toml",
+			content: `[[jobs]]
+name = "a"
+folder = "/external/a/"
+remote = true
+on_conflict = "replace"
+
+[jobs.media]
+type = "audio"
+`,
+			want: []ConfigIssue{
+				{
+					Path:    "jobs[0].on_conflict",
+					Line:    5,
+					Column:  1,
+					Message: `invalid conflict policy "replace", must be merge, suffix, parent-prefix, fail`,
+				},
+				{Path: "jobs[0].media.type", Line: 8, Column: 1, Message: `invalid media type "audio", must be image or video`},
+			},
+		},
+		{
+			name: "schema and option issues",
+			file: "mixed.yaml",
+			content: `defaults:
+  recursive: yes please
+jobs:
+  - name: a
+    folder: /missing/a/
+    exclude: ["***"]
+    skiplevels: 1
+`,
+			want: []ConfigIssue{
+				{Path: "defaults.recursive", Line: 2, Column: 3, Message: "must be a boolean"},
+				{Path: "jobs[0].folder", Line: 5, Column: 5, Message: `folder "/missing/a" does not exist`},
+				{Path: "jobs[0].exclude[0]", Line: 6, Column: 15, Message: `too many stars in "***"`},
+				{Path: "jobs[0].skiplevels", Line: 7, Column: 5, Message: `unknown field "skiplevels", did you mean "skip_levels"?`},
+			},
+		},
+		{
+			name:    "options outside jobs",
+			file:    "outside.json",
+			content: `{"recursive": true, "jobs": [{"name": "a", "folder": "` + photos + `"}]}`,
+			want: []ConfigIssue{{
+				Path:    "recursive",
+				Line:    1,
+				Column:  2,
+				Message: `unknown field "recursive", options must be set in defaults or jobs`,
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name := filepath.Join(tmp, tt.file)
+			if err := os.WriteFile(name, []byte(tt.content), 0o600); err != nil {
+				t.Fatalf("expected nil, got %v", err)
+			}
+
+			issues, err := ValidateAutoCreateConfig(name)
+			if err != nil {
+				t.Errorf("expected nil, got %v", err)
+			}
+
+			// syntax error messages come from the decoders, only the
+			// location is checked.
+			for i := range issues {
+				if strings.HasPrefix(issues[i].Message, "unable to decode") {
+					issues[i].Message = ""
+				}
+			}
+
+			if !reflect.DeepEqual(issues, tt.want) {
+				t.Errorf("unexpected issues: %+v (expected %+v)", issues, tt.want)
+			}
+		})
+	}
+}
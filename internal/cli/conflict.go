@@ -0,0 +1,171 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/faabiosr/imt/internal/errors"
+)
+
+// Conflict policies of different folders resolving to the same album name.
+const (
+	ConflictMerge        = "merge"
+	ConflictSuffix       = "suffix"
+	ConflictParentPrefix = "parent-prefix"
+	ConflictFail         = "fail"
+)
+
+// AlbumCollision represents an album name claimed by different folders or
+// shared by existing albums, and how it was resolved.
+type AlbumCollision struct {
+	Album      string
+	Conflict   string
+	Resolution string
+}
+
+// validateConflictPolicy checks the conflict policy.
+func validateConflictPolicy(policy string) error {
+	switch policy {
+	case "", ConflictMerge, ConflictSuffix, ConflictParentPrefix, ConflictFail:
+		return nil
+	default:
+		return errors.Errorf(
+			"invalid conflict policy %q, must be %s",
+			policy,
+			strings.Join([]string{ConflictMerge, ConflictSuffix, ConflictParentPrefix, ConflictFail}, ", "),
+		)
+	}
+}
+
+// albumOwner is the folder that claimed an album name first.
+type albumOwner struct {
+	folder string
+	name   string
+}
+
+// albumOwners tracks which folder claimed an album name, resolving the
+// collisions based on the conflict policy. Names are compared by their
+// normalized key, the name of the owner is the one displayed.
+type albumOwners struct {
+	policy     string
+	key        normalizer
+	owners     map[string]albumOwner
+	resolved   map[string]string
+	collisions []AlbumCollision
+}
+
+// newAlbumOwners creates the album owners tracker.
+func newAlbumOwners(policy string, key normalizer) *albumOwners {
+	return &albumOwners{
+		policy:   policy,
+		key:      key,
+		owners:   map[string]albumOwner{},
+		resolved: map[string]string{},
+	}
+}
+
+// resolve returns the album name of the folder. The first folder claiming a
+// name owns it, the next ones are resolved by the conflict policy. Once
+// resolved, a folder always gets the same name.
+func (o *albumOwners) resolve(name, folder, parent string) (string, error) {
+	if v, ok := o.resolved[folder]; ok {
+		return v, nil
+	}
+
+	owner, ok := o.owners[o.key(name)]
+	if !ok {
+		o.claim(name, folder)
+		return name, nil
+	}
+
+	if owner.folder == folder {
+		o.resolved[folder] = owner.name
+		return owner.name, nil
+	}
+
+	c := AlbumCollision{
+		Album:    name,
+		Conflict: fmt.Sprintf("%s collides with %s", folder, owner.folder),
+	}
+
+	switch o.policy {
+	case ConflictFail:
+		return "", errors.Errorf("album name %q collision: %s", name, c.Conflict)
+	case ConflictSuffix:
+		name = o.suffix(name)
+	case ConflictParentPrefix:
+		if parent != "" {
+			name = parent + " " + name
+		}
+
+		if o.taken(name) {
+			name = o.suffix(name)
+		}
+	default:
+		o.resolved[folder] = owner.name
+		c.Resolution = "merged into " + owner.name
+		o.collisions = append(o.collisions, c)
+
+		return owner.name, nil
+	}
+
+	o.claim(name, folder)
+
+	c.Resolution = "renamed to " + name
+	o.collisions = append(o.collisions, c)
+
+	return name, nil
+}
+
+// claim sets the folder as owner of the album name.
+func (o *albumOwners) claim(name, folder string) {
+	o.owners[o.key(name)] = albumOwner{folder: folder, name: name}
+	o.resolved[folder] = name
+}
+
+// taken checks if the album name is owned by a folder.
+func (o *albumOwners) taken(name string) bool {
+	_, ok := o.owners[o.key(name)]
+	return ok
+}
+
+// suffix returns the name with the first numeric suffix not taken.
+func (o *albumOwners) suffix(name string) string {
+	for i := 2; ; i++ {
+		s := fmt.Sprintf("%s (%d)", name, i)
+		if !o.taken(s) {
+			return s
+		}
+	}
+}
+
+// existingCollision checks if there are several existing albums with the
+// same name, failing or picking the first one based on the conflict policy.
+func existingCollision(policy, name string, albums Albums) (*AlbumCollision, error) {
+	if len(albums) < 2 {
+		return nil, nil
+	}
+
+	ids := make([]string, 0, len(albums))
+	for _, a := range albums {
+		ids = append(ids, a.ID)
+	}
+
+	c := &AlbumCollision{
+		Album:    name,
+		Conflict: fmt.Sprintf("%d existing albums: %s", len(albums), strings.Join(ids, ", ")),
+	}
+
+	if policy == ConflictFail {
+		return nil, errors.Errorf("album name %q collision: %s", name, c.Conflict)
+	}
+
+	c.Resolution = "merged into " + albums[0].ID
+
+	return c, nil
+}
@@ -0,0 +1,124 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package cli
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestConflict_validateConflictPolicy(t *testing.T) {
+	for _, p := range []string{"", ConflictMerge, ConflictSuffix, ConflictParentPrefix, ConflictFail} {
+		if err := validateConflictPolicy(p); err != nil {
+			t.Errorf("expected nil, got %v", err)
+		}
+	}
+
+	if err := validateConflictPolicy("rename"); err == nil {
+		t.Error("expected an error, got nil")
+	}
+}
+
+func TestConflict_albumOwners(t *testing.T) {
+	folders := []struct {
+		name, folder, parent string
+	}{
+		{"Beach", "/2022/Beach", "2022"},
+		{"Beach", "/2022/Beach", "2022"},
+		{"Beach", "/2023/Beach", "2023"},
+		{"2023 Beach", "/misc/2023 Beach", "misc"},
+		{"Beach", "/2024/Beach", "2023"},
+	}
+
+	tests := []struct {
+		policy     string
+		want       []string
+		collisions int
+		err        string
+	}{
+		{
+			policy:     ConflictMerge,
+			want:       []string{"Beach", "Beach", "Beach", "2023 Beach", "Beach"},
+			collisions: 2,
+		},
+		{
+			policy:     ConflictSuffix,
+			want:       []string{"Beach", "Beach", "Beach (2)", "2023 Beach", "Beach (3)"},
+			collisions: 2,
+		},
+		{
+			policy:     ConflictParentPrefix,
+			want:       []string{"Beach", "Beach", "2023 Beach", "misc 2023 Beach", "2023 Beach (2)"},
+			collisions: 3,
+		},
+		{
+			policy: ConflictFail,
+			err:    `album name "Beach" collision: /2023/Beach collides with /2022/Beach`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			o := newAlbumOwners(tt.policy, func(s string) string { return s })
+
+			var got []string
+
+			for _, f := range folders {
+				name, err := o.resolve(f.name, f.folder, f.parent)
+				if err != nil {
+					if tt.err == "" || err.Error() != tt.err {
+						t.Errorf("unexpected error: %v (expected %s)", err, tt.err)
+					}
+
+					return
+				}
+
+				got = append(got, name)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("unexpected names: %v (expected %v)", got, tt.want)
+			}
+
+			if n := len(o.collisions); n != tt.collisions {
+				t.Errorf("unexpected number of collisions: %d (expected %d)", n, tt.collisions)
+			}
+		})
+	}
+}
+
+func TestConflict_existingCollision(t *testing.T) {
+	albums := Albums{
+		{ID: "821256df-77e9-4616-91b9-57465995a01b", Name: "Beach"},
+		{ID: "4cbd308b-ed70-4fe9-92f3-ad4ac3ee8710", Name: "Beach"},
+	}
+
+	t.Run("single album", func(t *testing.T) {
+		c, err := existingCollision(ConflictFail, "Beach", albums[:1])
+		if c != nil || err != nil {
+			t.Errorf("expected nil, got %v, %v", c, err)
+		}
+	})
+
+	t.Run("fail", func(t *testing.T) {
+		_, err := existingCollision(ConflictFail, "Beach", albums)
+		if err == nil || !strings.Contains(err.Error(), "2 existing albums") {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("merge", func(t *testing.T) {
+		c, err := existingCollision(ConflictSuffix, "Beach", albums)
+		if err != nil {
+			t.Errorf("expected nil, got %v", err)
+		}
+
+		if expected := "merged into " + albums[0].ID; c.Resolution != expected {
+			t.Errorf("unexpected resolution: %s (expected %s)", c.Resolution, expected)
+		}
+	})
+}
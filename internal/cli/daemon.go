@@ -0,0 +1,510 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	"github.com/faabiosr/imt/internal/client"
+	"github.com/faabiosr/imt/internal/errors"
+)
+
+// daemonShutdownTimeout is the time waited for the health server to close.
+const daemonShutdownTimeout = 5 * time.Second
+
+// DaemonConfig handles the jobs run by the daemon and the address of the
+// health endpoint, which is disabled when empty.
+type DaemonConfig struct {
+	Listen string      `json:"listen,omitempty"`
+	Jobs   []DaemonJob `json:"jobs"`
+}
+
+// DaemonJob is a job run on a cron schedule, like "0 * * * *" or "@hourly".
+// Exactly one kind of job must be set.
+type DaemonJob struct {
+	Name       string                   `json:"name"`
+	Schedule   string                   `json:"schedule"`
+	AutoCreate *AutoCreateAlbumsOptions `json:"auto_create,omitempty"`
+	Sync       *SyncJobOptions          `json:"sync,omitempty"`
+	Prune      *PruneJobOptions         `json:"prune,omitempty"`
+	Report     *ReportJobOptions        `json:"report,omitempty"`
+}
+
+// SyncJobOptions handles the options of the jobs refreshing smart albums,
+// all the definitions are refreshed when no albums are given.
+type SyncJobOptions struct {
+	Definitions string   `json:"definitions,omitempty"`
+	Albums      []string `json:"albums,omitempty"`
+	Remove      bool     `json:"remove,omitempty"`
+}
+
+// PruneJobOptions handles the options of the jobs deleting the albums found
+// by the prune, which are only logged in dry run.
+type PruneJobOptions struct {
+	Kinds     []string `json:"kinds,omitempty" enum:"empty,trashed,duplicate-assets,duplicate-name"`
+	Normalize []string `json:"normalize,omitempty" enum:"nfc,casefold,whitespace"`
+	DryRun    bool     `json:"dry_run,omitempty"`
+}
+
+// ReportJobOptions handles the options of the jobs building the library
+// report, written as JSON into the output file when set.
+type ReportJobOptions struct {
+	Output string `json:"output,omitempty"`
+}
+
+// daemonJobKinds are the config keys of the job kinds.
+const daemonJobKinds = "auto_create, sync, prune or report"
+
+// DaemonJobStatus is the status of a daemon job.
+type DaemonJobStatus struct {
+	Name      string    `json:"name"`
+	Schedule  string    `json:"schedule"`
+	Running   bool      `json:"running"`
+	LastRun   time.Time `json:"last_run,omitzero"`
+	LastError string    `json:"last_error,omitempty"`
+	NextRun   time.Time `json:"next_run,omitzero"`
+}
+
+// LoadDaemonConfig reads the daemon config from a JSON, YAML or TOML config
+// file, the format is chosen by the file extension. Unknown fields are not
+// allowed.
+func LoadDaemonConfig(name string) (*DaemonConfig, error) {
+	f, err := readConfigFile(name)
+	if err != nil {
+		return nil, err
+	}
+
+	issues := checkValue(f.doc, reflect.TypeFor[DaemonConfig](), "")
+
+	var cfg DaemonConfig
+
+	if len(issues) == 0 {
+		content, err := json.Marshal(configValue(f.doc))
+		if err != nil {
+			return nil, errors.Errorf("unable to decode daemon config: %w", err)
+		}
+
+		if err := json.Unmarshal(content, &cfg); err != nil {
+			return nil, errors.Errorf("unable to decode daemon config: %w", err)
+		}
+
+		issues = cfg.validate()
+	}
+
+	if len(issues) == 0 {
+		return &cfg, nil
+	}
+
+	errs := make([]error, 0, len(issues))
+	for _, issue := range f.locate(issues) {
+		errs = append(errs, issue)
+	}
+
+	return nil, errors.Errorf("invalid daemon config file:\n%w", errors.Join(errs...))
+}
+
+// validate checks the jobs, the paths of the issues are relative to the
+// config.
+func (c *DaemonConfig) validate() []ConfigIssue {
+	if len(c.Jobs) == 0 {
+		return []ConfigIssue{{Path: "jobs", Message: "at least one job is required"}}
+	}
+
+	var issues []ConfigIssue
+
+	names := map[string]bool{}
+
+	for i, job := range c.Jobs {
+		path := fmt.Sprintf("jobs[%d]", i)
+
+		add := func(rel, msg string) {
+			issue := ConfigIssue{Path: path, Message: msg}
+			if rel != "" {
+				issue.Path = joinPath(path, rel)
+			}
+
+			issues = append(issues, issue)
+		}
+
+		switch {
+		case job.Name == "":
+			add("name", "name is required")
+		case names[job.Name]:
+			add("name", fmt.Sprintf("duplicate job name %q", job.Name))
+		}
+
+		names[job.Name] = true
+
+		if _, err := cron.ParseStandard(job.Schedule); err != nil {
+			add("schedule", fmt.Sprintf("invalid schedule %q: %v", job.Schedule, err))
+		}
+
+		if n := job.kinds(); n != 1 {
+			msg := "a job kind is required: "
+			if n > 1 {
+				msg = "only one job kind is allowed: "
+			}
+
+			add("", msg+daemonJobKinds)
+
+			continue
+		}
+
+		switch {
+		case job.AutoCreate != nil:
+			for _, issue := range validateAutoCreateOptions(job.AutoCreate) {
+				add(joinPath("auto_create", issue.Path), issue.Message)
+			}
+		case job.Prune != nil:
+			if len(job.Prune.Kinds) == 0 {
+				add("prune", "kinds are required: "+strings.Join(pruneKinds, ", "))
+			} else if _, err := selectPruneKinds(job.Prune.Kinds); err != nil {
+				add("prune.kinds", err.Error())
+			}
+
+			if _, err := newNormalizer(job.Prune.Normalize); err != nil {
+				add("prune.normalize", err.Error())
+			}
+		}
+	}
+
+	return issues
+}
+
+// kinds returns the number of job kinds set.
+func (j *DaemonJob) kinds() int {
+	n := 0
+
+	for _, set := range []bool{j.AutoCreate != nil, j.Sync != nil, j.Prune != nil, j.Report != nil} {
+		if set {
+			n++
+		}
+	}
+
+	return n
+}
+
+// changesAlbums checks if the job creates, fills or deletes albums.
+func (j *DaemonJob) changesAlbums() bool {
+	return j.AutoCreate != nil || j.Sync != nil || (j.Prune != nil && !j.Prune.DryRun)
+}
+
+// run runs the job, returning the attributes logged.
+func (j *DaemonJob) run(ctx context.Context, cl *client.Client) ([]any, error) {
+	switch {
+	case j.Sync != nil:
+		return j.Sync.run(ctx, cl)
+	case j.Prune != nil:
+		return j.Prune.run(ctx, cl)
+	case j.Report != nil:
+		return j.Report.run(ctx, cl)
+	}
+
+	report, err := AutoCreateAlbums(ctx, cl, j.AutoCreate)
+	if err != nil {
+		return nil, err
+	}
+
+	return []any{
+		"albums_created", len(report.Created),
+		"assets_added", report.Assets,
+		"collisions", len(report.Collisions),
+		"skipped_folders", len(report.Skipped),
+	}, nil
+}
+
+// run refreshes the smart albums.
+func (o *SyncJobOptions) run(ctx context.Context, cl *client.Client) ([]any, error) {
+	name := o.Definitions
+	if name == "" {
+		var err error
+		if name, err = DefaultSmartAlbumsPath(); err != nil {
+			return nil, err
+		}
+	}
+
+	albums, err := LoadSmartAlbums(name)
+	if err != nil {
+		return nil, err
+	}
+
+	albums, err = SelectSmartAlbums(albums, o.Albums)
+	if err != nil {
+		return nil, err
+	}
+
+	reports, err := RefreshSmartAlbums(ctx, cl, albums, o.Remove)
+
+	created, added, removed := 0, 0, 0
+
+	for _, r := range reports {
+		if r.Created {
+			created++
+		}
+
+		added += r.Added
+		removed += r.Removed
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return []any{
+		"albums_refreshed", len(reports),
+		"albums_created", created,
+		"assets_added", added,
+		"assets_removed", removed,
+	}, nil
+}
+
+// run deletes the albums found by the prune.
+func (o *PruneJobOptions) run(ctx context.Context, cl *client.Client) ([]any, error) {
+	candidates, err := FindPruneCandidates(ctx, cl, &PruneOptions{Kinds: o.Kinds, Normalize: o.Normalize})
+	if err != nil {
+		return nil, err
+	}
+
+	as := make(Albums, 0, len(candidates))
+	for _, c := range candidates {
+		as = append(as, c.Album)
+	}
+
+	if o.DryRun {
+		return []any{"albums_found", len(as), "dry_run", true}, nil
+	}
+
+	if err := DeleteAlbums(ctx, cl, as); err != nil {
+		return nil, err
+	}
+
+	return []any{"albums_deleted", len(as)}, nil
+}
+
+// run builds the library report, writing it into the output file.
+func (o *ReportJobOptions) run(ctx context.Context, cl *client.Client) ([]any, error) {
+	r, err := NewLibraryReport(ctx, cl)
+	if err != nil {
+		return nil, err
+	}
+
+	if o.Output != "" {
+		if err := WriteLibraryReport(o.Output, r); err != nil {
+			return nil, err
+		}
+	}
+
+	return []any{
+		"photos", r.Photos,
+		"videos", r.Videos,
+		"albums", r.Albums,
+		"empty_albums", r.EmptyAlbums,
+		"shared_links", r.SharedLinks,
+	}, nil
+}
+
+// Daemon runs the config jobs on their schedules inside one process.
+type Daemon struct {
+	ctx    context.Context
+	cl     *client.Client
+	cfg    *DaemonConfig
+	logger *slog.Logger
+	cron   *cron.Cron
+	jobs   []*daemonJob
+
+	// albums serializes the jobs changing albums, which would otherwise
+	// race on the same albums, like creating one twice.
+	albums sync.Mutex
+}
+
+// daemonJob is a scheduled job, whose runs never overlap.
+type daemonJob struct {
+	DaemonJob
+
+	entry   cron.EntryID
+	running atomic.Bool
+
+	mu        sync.Mutex
+	lastRun   time.Time
+	lastError string
+}
+
+// NewDaemon creates a daemon scheduling the config jobs.
+func NewDaemon(cl *client.Client, cfg *DaemonConfig, logger *slog.Logger) (*Daemon, error) {
+	d := &Daemon{
+		cl:     cl,
+		cfg:    cfg,
+		logger: logger,
+		cron:   cron.New(),
+	}
+
+	for _, job := range cfg.Jobs {
+		j := &daemonJob{DaemonJob: job}
+
+		entry, err := d.cron.AddFunc(j.Schedule, func() { d.run(d.ctx, j) })
+		if err != nil {
+			return nil, errors.Errorf("invalid schedule of job %q: %w", j.Name, err)
+		}
+
+		j.entry = entry
+		d.jobs = append(d.jobs, j)
+	}
+
+	return d, nil
+}
+
+// Run starts the scheduler and the health endpoint, blocking until the
+// context is done. On shutdown, the running jobs are cancelled through the
+// context and waited for.
+func (d *Daemon) Run(ctx context.Context) error {
+	d.ctx = ctx
+
+	errs := make(chan error, 1)
+
+	var srv *http.Server
+
+	if d.cfg.Listen != "" {
+		ln, err := net.Listen("tcp", d.cfg.Listen)
+		if err != nil {
+			return errors.Errorf("unable to listen on %s: %w", d.cfg.Listen, err)
+		}
+
+		srv = &http.Server{Handler: d.Handler(), ReadHeaderTimeout: daemonShutdownTimeout}
+
+		go func() {
+			if err := srv.Serve(ln); !errors.Is(err, http.ErrServerClosed) {
+				errs <- err
+			}
+		}()
+
+		d.logger.Info("health endpoint listening", "addr", ln.Addr().String())
+	}
+
+	d.cron.Start()
+	d.logger.Info("daemon started", "jobs", len(d.jobs))
+
+	var err error
+
+	select {
+	case <-ctx.Done():
+	case err = <-errs:
+		d.logger.Error("health endpoint failed", "error", err)
+	}
+
+	d.logger.Info("daemon stopping")
+
+	<-d.cron.Stop().Done()
+
+	if srv != nil {
+		sctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), daemonShutdownTimeout)
+		defer cancel()
+
+		if serr := srv.Shutdown(sctx); serr != nil && err == nil {
+			err = serr
+		}
+	}
+
+	d.logger.Info("daemon stopped")
+
+	return err
+}
+
+// run runs the job unless a previous run is still running. The jobs changing
+// albums wait for each other.
+func (d *Daemon) run(ctx context.Context, j *daemonJob) {
+	logger := d.logger.With("job", j.Name)
+
+	if !j.running.CompareAndSwap(false, true) {
+		logger.Warn("job skipped, previous run still running")
+		return
+	}
+
+	defer j.running.Store(false)
+
+	if j.changesAlbums() {
+		d.albums.Lock()
+		defer d.albums.Unlock()
+	}
+
+	start := time.Now()
+
+	logger.Info("job started")
+
+	attrs, err := j.run(ctx, d.cl)
+
+	j.mu.Lock()
+	j.lastRun = start
+	j.lastError = ""
+
+	if err != nil {
+		j.lastError = err.Error()
+	}
+
+	j.mu.Unlock()
+
+	if err != nil {
+		logger.Error("job failed", "duration", elapsed(start), "error", err)
+		return
+	}
+
+	logger.Info("job finished", append(attrs, "duration", elapsed(start))...)
+}
+
+// elapsed returns the time elapsed since start, rounded to milliseconds.
+func elapsed(start time.Time) string {
+	return time.Since(start).Round(time.Millisecond).String()
+}
+
+// Status returns the status of the jobs.
+func (d *Daemon) Status() []DaemonJobStatus {
+	status := make([]DaemonJobStatus, 0, len(d.jobs))
+
+	for _, j := range d.jobs {
+		j.mu.Lock()
+
+		status = append(status, DaemonJobStatus{
+			Name:      j.Name,
+			Schedule:  j.Schedule,
+			Running:   j.running.Load(),
+			LastRun:   j.lastRun,
+			LastError: j.lastError,
+			NextRun:   d.cron.Entry(j.entry).Next,
+		})
+
+		j.mu.Unlock()
+	}
+
+	return status
+}
+
+// Handler returns the HTTP handler of the health endpoint, /healthz, which
+// responds with the status of the jobs.
+func (d *Daemon) Handler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"status": "ok",
+			"jobs":   d.Status(),
+		})
+	})
+
+	return mux
+}
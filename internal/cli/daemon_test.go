@@ -0,0 +1,356 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+
+	"github.com/faabiosr/imt/internal/client"
+)
+
+func TestDaemon_LoadDaemonConfig(t *testing.T) {
+	tmp := t.TempDir()
+
+	tests := []struct {
+		name    string
+		content string
+		err     string
+	}{
+		{
+			name: "valid",
+			content: `listen: ":8080"
+jobs:
+  - name: photos
+    schedule: "@hourly"
+    auto_create:
+      folder: ` + tmp + `/
+      recursive: true
+`,
+		},
+		{
+			name:    "without jobs",
+			content: "jobs: []\n",
+			err:     "jobs: at least one job is required",
+		},
+		{
+			name: "unknown field",
+			content: `jobs:
+  - name: photos
+    schedule: "@hourly"
+    auto_create:
+      folder: /photos/
+      recurse: true
+`,
+			err: `line 6, column 7: jobs[0].auto_create.recurse: unknown field "recurse", did you mean "recursive"?`,
+		},
+		{
+			name: "invalid jobs",
+			content: `jobs:
+  - name: photos
+    schedule: "every hour"
+    auto_create:
+      folder: ` + tmp + `/
+      skip_levels: -1
+  - name: photos
+    schedule: "0 * * * *"
+`,
+			err: strings.Join([]string{
+				`line 3, column 5: jobs[0].schedule: invalid schedule "every hour"`,
+				"line 6, column 7: jobs[0].auto_create.skip_levels: must not be negative",
+				`line 7, column 5: jobs[1].name: duplicate job name "photos"`,
+				"line 7, column 5: jobs[1]: a job kind is required: auto_create, sync, prune or report",
+			}, "|"),
+		},
+		{
+			name: "invalid job kinds",
+			content: `jobs:
+  - name: photos
+    schedule: "@hourly"
+    auto_create:
+      folder: ` + tmp + `/
+    report: {}
+  - name: prune
+    schedule: "@daily"
+    prune:
+      kinds: [empty, old]
+      normalize: [upper]
+  - name: prune all
+    schedule: "@daily"
+    prune:
+      dry_run: true
+`,
+			err: strings.Join([]string{
+				"line 2, column 5: jobs[0]: only one job kind is allowed: auto_create, sync, prune or report",
+				`line 10, column 7: jobs[1].prune.kinds: invalid prune kind "old"`,
+				"line 11, column 7: jobs[1].prune.normalize:",
+				"line 14, column 5: jobs[2].prune: kinds are required: empty, trashed, duplicate-assets, duplicate-name",
+			}, "|"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name := filepath.Join(t.TempDir(), "daemon.yaml")
+
+			if err := os.WriteFile(name, []byte(tt.content), 0o600); err != nil {
+				t.Fatalf("expected nil, got %v", err)
+			}
+
+			cfg, err := LoadDaemonConfig(name)
+
+			if tt.err == "" {
+				if err != nil {
+					t.Fatalf("expected nil, got %v", err)
+				}
+
+				if cfg.Listen != ":8080" || len(cfg.Jobs) != 1 || !cfg.Jobs[0].AutoCreate.Recursive {
+					t.Errorf("unexpected config: %+v", cfg)
+				}
+
+				return
+			}
+
+			if err == nil {
+				t.Fatal("expected an error, got nil")
+			}
+
+			for _, msg := range strings.Split(tt.err, "|") {
+				if !strings.Contains(err.Error(), msg) {
+					t.Errorf("unexpected error: %v (expected %s)", err, msg)
+				}
+			}
+		})
+	}
+}
+
+func TestDaemon_jobs(t *testing.T) {
+	hc := http.DefaultClient
+
+	httpmock.ActivateNonDefault(hc)
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder(
+		http.MethodGet,
+		testHost+"/api/server/statistics",
+		httpmock.NewStringResponder(http.StatusOK, `{"photos": 10, "videos": 2, "usage": 2048}`),
+	)
+	httpmock.RegisterResponder(
+		http.MethodGet,
+		testHost+"/api/albums",
+		httpmock.NewStringResponder(http.StatusOK, `[{"id": "a1", "albumName": "Trip", "assetCount": 3}, {"id": "a2", "albumName": "Empty"}]`),
+	)
+	httpmock.RegisterResponder(http.MethodGet, testHost+"/api/shared-links", httpmock.NewStringResponder(http.StatusOK, `[{"id": "l1"}]`))
+
+	baseURL, _ := url.Parse(testHost)
+	cl := client.NewWithHTTPClient(baseURL, testAPIKey, hc)
+
+	t.Run("report", func(t *testing.T) {
+		name := filepath.Join(t.TempDir(), "report.json")
+		job := &DaemonJob{Name: "report", Schedule: "@daily", Report: &ReportJobOptions{Output: name}}
+
+		attrs, err := job.run(context.Background(), cl)
+		if err != nil {
+			t.Fatalf("expected nil, got %v", err)
+		}
+
+		expected := []any{"photos", int64(10), "videos", int64(2), "albums", 2, "empty_albums", 1, "shared_links", 1}
+		if !reflect.DeepEqual(attrs, expected) {
+			t.Errorf("unexpected attrs: %v (expected %v)", attrs, expected)
+		}
+
+		content, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatalf("expected nil, got %v", err)
+		}
+
+		var r LibraryReport
+		if err := json.Unmarshal(content, &r); err != nil {
+			t.Fatalf("expected nil, got %v", err)
+		}
+
+		if r.Usage != 2048 || r.EmptyAlbums != 1 || r.GeneratedAt.IsZero() {
+			t.Errorf("unexpected report: %+v", r)
+		}
+	})
+
+	t.Run("prune dry run", func(t *testing.T) {
+		job := &DaemonJob{Name: "prune", Schedule: "@daily", Prune: &PruneJobOptions{Kinds: []string{"empty"}, DryRun: true}}
+
+		attrs, err := job.run(context.Background(), cl)
+		if err != nil {
+			t.Fatalf("expected nil, got %v", err)
+		}
+
+		if expected := []any{"albums_found", 1, "dry_run", true}; !reflect.DeepEqual(attrs, expected) {
+			t.Errorf("unexpected attrs: %v (expected %v)", attrs, expected)
+		}
+
+		if n := httpmock.GetCallCountInfo()["DELETE "+testHost+"/api/albums/a2"]; n != 0 {
+			t.Errorf("unexpected albums deleted: %d (expected 0)", n)
+		}
+	})
+
+	t.Run("sync", func(t *testing.T) {
+		opts := &SyncJobOptions{Definitions: filepath.Join(t.TempDir(), "smart_albums.json")}
+		job := &DaemonJob{Name: "sync", Schedule: "@daily", Sync: opts}
+
+		attrs, err := job.run(context.Background(), cl)
+		if err != nil {
+			t.Fatalf("expected nil, got %v", err)
+		}
+
+		if expected := []any{"albums_refreshed", 0, "albums_created", 0, "assets_added", 0, "assets_removed", 0}; !reflect.DeepEqual(attrs, expected) {
+			t.Errorf("unexpected attrs: %v (expected %v)", attrs, expected)
+		}
+
+		opts.Albums = []string{"Trips"}
+
+		if _, err := job.run(context.Background(), cl); err == nil {
+			t.Error("expected an error, got nil")
+		}
+	})
+}
+
+func TestDaemon(t *testing.T) {
+	hc := http.DefaultClient
+
+	httpmock.ActivateNonDefault(hc)
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder(
+		http.MethodGet,
+		testHost+"/api/view/folder/unique-paths",
+		httpmock.NewJsonResponderOrPanic(http.StatusInternalServerError, json.RawMessage(`{"message": "failed"}`)),
+	)
+
+	baseURL, _ := url.Parse(testHost)
+	cl := client.NewWithHTTPClient(baseURL, testAPIKey, hc)
+
+	cfg := &DaemonConfig{
+		Listen: "127.0.0.1:0",
+		Jobs: []DaemonJob{
+			{Name: "photos", Schedule: "@hourly", AutoCreate: &AutoCreateAlbumsOptions{Folder: "/photos/", Remote: true}},
+		},
+	}
+
+	var logs bytes.Buffer
+
+	d, err := NewDaemon(cl, cfg, slog.New(slog.NewJSONHandler(&logs, nil)))
+	if err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+
+	t.Run("invalid schedule", func(t *testing.T) {
+		cfg := &DaemonConfig{Jobs: []DaemonJob{{Name: "photos", Schedule: "every hour"}}}
+
+		if _, err := NewDaemon(cl, cfg, slog.New(slog.NewJSONHandler(io.Discard, nil))); err == nil {
+			t.Error("expected an error, got nil")
+		}
+	})
+
+	t.Run("runs do not overlap", func(t *testing.T) {
+		logs.Reset()
+
+		j := d.jobs[0]
+		j.running.Store(true)
+		d.run(context.Background(), j)
+		j.running.Store(false)
+
+		if !strings.Contains(logs.String(), "job skipped") {
+			t.Errorf("unexpected logs: %s (expected job skipped)", logs.String())
+		}
+	})
+
+	t.Run("album jobs are serialized", func(t *testing.T) {
+		d.albums.Lock()
+
+		done := make(chan struct{})
+
+		go func() {
+			d.run(context.Background(), d.jobs[0])
+			close(done)
+		}()
+
+		select {
+		case <-done:
+			t.Error("expected the job to wait for the running album job")
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		d.albums.Unlock()
+
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("expected the job to run")
+		}
+	})
+
+	t.Run("failed run status", func(t *testing.T) {
+		d.run(context.Background(), d.jobs[0])
+
+		rec := httptest.NewRecorder()
+		d.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+		if rec.Code != http.StatusOK {
+			t.Errorf("unexpected status code: %d (expected %d)", rec.Code, http.StatusOK)
+		}
+
+		var health struct {
+			Status string            `json:"status"`
+			Jobs   []DaemonJobStatus `json:"jobs"`
+		}
+
+		if err := json.NewDecoder(rec.Body).Decode(&health); err != nil {
+			t.Fatalf("expected nil, got %v", err)
+		}
+
+		if len(health.Jobs) != 1 || health.Jobs[0].LastRun.IsZero() || health.Jobs[0].LastError == "" {
+			t.Errorf("unexpected jobs status: %+v", health.Jobs)
+		}
+	})
+
+	t.Run("graceful shutdown", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+
+		done := make(chan error)
+
+		go func() {
+			done <- d.Run(ctx)
+		}()
+
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Errorf("expected nil, got %v", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("expected the daemon to stop")
+		}
+
+		if !strings.Contains(logs.String(), "daemon stopped") {
+			t.Errorf("unexpected logs: %s (expected daemon stopped)", logs.String())
+		}
+	})
+}
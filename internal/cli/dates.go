@@ -0,0 +1,224 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package cli
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/faabiosr/imt/internal/errors"
+)
+
+// Date grouping policies of folders with the same name across years.
+const (
+	DateGroupMerge = "merge"
+	DateGroupYear  = "year"
+)
+
+// defaultDateLayouts are the layouts detected when none is set.
+var defaultDateLayouts = []string{
+	"2006-01-02",
+	"2006_01_02",
+	"2006.01.02",
+	"20060102",
+	"2006-01",
+	"2006",
+}
+
+// FolderDateOptions handles the date detection in folder names. The date
+// prefix or suffix is stripped from the album name.
+type FolderDateOptions struct {
+	// Layouts are the Go time layouts detected, they must be fixed width.
+	Layouts []string `json:"layouts,omitempty"`
+
+	// Description is the Go time layout used to set the album description.
+	Description string `json:"description,omitempty"`
+
+	// Group is the policy for folders with the same name across years,
+	// "merge" creates one album and "year" creates year suffixed albums.
+	Group string `json:"group,omitempty" enum:"merge,year"`
+}
+
+// validate checks the date options.
+func (o *FolderDateOptions) validate() error {
+	switch o.Group {
+	case "", DateGroupMerge, DateGroupYear:
+	default:
+		return errors.Errorf("invalid date group policy %q, must be %s or %s", o.Group, DateGroupMerge, DateGroupYear)
+	}
+
+	for _, l := range o.Layouts {
+		if strings.TrimSpace(l) == "" {
+			return errors.New("empty date layout is not allowed")
+		}
+	}
+
+	return nil
+}
+
+// layouts returns the layouts set or the default ones.
+func (o *FolderDateOptions) layouts() []string {
+	if len(o.Layouts) > 0 {
+		return o.Layouts
+	}
+
+	return defaultDateLayouts
+}
+
+const dateSeparators = " _-.,"
+
+// stripFolderDate detects a date prefix or suffix in the folder name, and
+// returns the name without it. The name is returned as is when no date is
+// found or nothing is left after stripping it.
+func stripFolderDate(name string, layouts []string) (string, time.Time, bool) {
+	if isFolderDate(name, layouts) {
+		return name, time.Time{}, false
+	}
+
+	for _, l := range layouts {
+		if len(name) < len(l) {
+			continue
+		}
+
+		if t, ok := parseLayout(l, name[:len(l)]); ok && separated(name, len(l)) {
+			if rest := strings.TrimLeft(name[len(l):], dateSeparators); rest != "" {
+				return rest, t, true
+			}
+		}
+
+		start := len(name) - len(l)
+		if t, ok := parseLayout(l, name[start:]); ok && separated(name, start) {
+			if rest := strings.TrimRight(name[:start], dateSeparators); rest != "" {
+				return rest, t, true
+			}
+		}
+	}
+
+	return name, time.Time{}, false
+}
+
+// isFolderDate checks if the whole folder name is a date of the layouts.
+func isFolderDate(name string, layouts []string) bool {
+	for _, l := range layouts {
+		if _, ok := parseLayout(l, name); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseLayout parses the value with the layout, the year must be in the range
+// of the folder dates.
+func parseLayout(layout, value string) (time.Time, bool) {
+	t, err := time.Parse(layout, value)
+	if err != nil || !validYear(t.Year()) {
+		return time.Time{}, false
+	}
+
+	return t, true
+}
+
+// separated checks if the name is split by a separator at the index, to avoid
+// matching digits that are part of a word.
+func separated(name string, i int) bool {
+	if i == 0 || i == len(name) {
+		return true
+	}
+
+	return strings.ContainsRune(dateSeparators, rune(name[i-1])) ||
+		strings.ContainsRune(dateSeparators, rune(name[i]))
+}
+
+// yearSuffix appends the year to the album name.
+func yearSuffix(name string, t time.Time) string {
+	return fmt.Sprintf("%s (%d)", name, t.Year())
+}
+
+var (
+	fullDateRe = regexp.MustCompile(`^(\d{4})[-_.]?(\d{2})[-_.]?(\d{2})(?:\D|$)`)
+	yearRe     = regexp.MustCompile(`^(\d{4})(?:\D|$)`)
+	twoDigitRe = regexp.MustCompile(`^(\d{2})(?:\D|$)`)
+)
+
+const (
+	minYear = 1800
+	maxYear = 2999
+	months  = 12
+	days    = 31
+)
+
+// parseFolderDate finds a date in the folder names, it can be a full date
+// prefix like "2024-05-12 Birthday" or be spread across the folders like
+// "2023/07 - Lisbon".
+func parseFolderDate(segments []string) time.Time {
+	year, month, day := 0, 0, 0
+
+	for _, s := range segments {
+		if m := fullDateRe.FindStringSubmatch(s); m != nil && validDate(m[1], m[2], m[3]) {
+			year, month, day = atoi(m[1]), atoi(m[2]), atoi(m[3])
+			continue
+		}
+
+		if m := yearRe.FindStringSubmatch(s); m != nil && validDate(m[1], "", "") {
+			year, month, day = atoi(m[1]), 0, 0
+			continue
+		}
+
+		m := twoDigitRe.FindStringSubmatch(s)
+		if m == nil || year == 0 {
+			continue
+		}
+
+		switch {
+		case month == 0 && validDate(strconv.Itoa(year), m[1], ""):
+			month = atoi(m[1])
+		case month > 0 && day == 0 && validDate(strconv.Itoa(year), strconv.Itoa(month), m[1]):
+			day = atoi(m[1])
+		}
+	}
+
+	if year == 0 {
+		return time.Time{}
+	}
+
+	return time.Date(year, time.Month(max(month, 1)), max(day, 1), 0, 0, 0, 0, time.UTC)
+}
+
+// validDate checks the date parts, empty parts are ignored.
+func validDate(year, month, day string) bool {
+	if !validYear(atoi(year)) {
+		return false
+	}
+
+	if month != "" {
+		if m := atoi(month); m < 1 || m > months {
+			return false
+		}
+	}
+
+	if day != "" {
+		if d := atoi(day); d < 1 || d > days {
+			return false
+		}
+	}
+
+	return true
+}
+
+// validYear checks the year is in the range of the folder dates.
+func validYear(year int) bool {
+	return year >= minYear && year <= maxYear
+}
+
+// atoi converts a string to int, returning zero when it is not a number.
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
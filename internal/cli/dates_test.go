@@ -0,0 +1,172 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package cli
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDates_parseFolderDate(t *testing.T) {
+	tests := []struct {
+		segments []string
+		want     time.Time
+	}{
+		{[]string{"photos", "trip"}, time.Time{}},
+		{[]string{"2023", "07 - Lisbon", "day2"}, time.Date(2023, 7, 1, 0, 0, 0, 0, time.UTC)},
+		{[]string{"2024-05-12 Birthday"}, time.Date(2024, 5, 12, 0, 0, 0, 0, time.UTC)},
+		{[]string{"20240512_Trip"}, time.Date(2024, 5, 12, 0, 0, 0, 0, time.UTC)},
+		{[]string{"2023", "13", "01"}, time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{[]string{"2023", "02", "14 Valentine"}, time.Date(2023, 2, 14, 0, 0, 0, 0, time.UTC)},
+		{[]string{"1080p"}, time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.segments, "/"), func(t *testing.T) {
+			if got := parseFolderDate(tt.segments); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("unexpected date: %v (expected %v)", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDates_stripFolderDate(t *testing.T) {
+	tests := []struct {
+		name    string
+		layouts []string
+		want    string
+		date    time.Time
+		found   bool
+	}{
+		{"2024-05-12 Birthday", defaultDateLayouts, "Birthday", time.Date(2024, 5, 12, 0, 0, 0, 0, time.UTC), true},
+		{"20240512_Trip", defaultDateLayouts, "Trip", time.Date(2024, 5, 12, 0, 0, 0, 0, time.UTC), true},
+		{"Trip - 2024-05", defaultDateLayouts, "Trip", time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), true},
+		{"Beach 2023", defaultDateLayouts, "Beach", time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), true},
+		{"2024-05-12", defaultDateLayouts, "2024-05-12", time.Time{}, false},
+		{"Route66", defaultDateLayouts, "Route66", time.Time{}, false},
+		{"1000 Islands", defaultDateLayouts, "1000 Islands", time.Time{}, false},
+		{"Trip 3000", defaultDateLayouts, "Trip 3000", time.Time{}, false},
+		{"12.05.2024 Party", []string{"02.01.2006"}, "Party", time.Date(2024, 5, 12, 0, 0, 0, 0, time.UTC), true},
+		{"12.05.2024 Party", []string{"2006"}, "12.05.2024 Party", time.Time{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, date, found := stripFolderDate(tt.name, tt.layouts)
+			if got != tt.want || !date.Equal(tt.date) || found != tt.found {
+				t.Errorf(
+					"unexpected result: %s, %v, %t (expected %s, %v, %t)",
+					got, date, found, tt.want, tt.date, tt.found,
+				)
+			}
+		})
+	}
+}
+
+func TestDates_validate(t *testing.T) {
+	tests := []struct {
+		opts FolderDateOptions
+		err  string
+	}{
+		{FolderDateOptions{}, ""},
+		{FolderDateOptions{Group: DateGroupYear}, ""},
+		{FolderDateOptions{Group: "month"}, "invalid date group policy"},
+		{FolderDateOptions{Layouts: []string{" "}}, "empty date layout"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.err, func(t *testing.T) {
+			var err string
+			if e := tt.opts.validate(); e != nil {
+				err = e.Error()
+			}
+
+			if !strings.Contains(err, tt.err) || (tt.err == "" && err != "") {
+				t.Errorf("unexpected error: %s (expected %s)", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestDates_albumNamer(t *testing.T) {
+	paths := []string{
+		"/2024/2024-07-10 Beach",
+		"/2023/2023-08-01 Beach",
+		"/misc/Friends",
+		"/2022/Lisbon 2022-03",
+		"/2021",
+		"/misc/1000 Islands",
+	}
+
+	tests := []struct {
+		name   string
+		group  string
+		albums []string
+		dates  map[string]string
+	}{
+		{
+			name:   "merge",
+			group:  DateGroupMerge,
+			albums: []string{"2021", "Lisbon", "Beach", "1000 Islands", "Friends"},
+			dates:  map[string]string{"Beach": "August 2023", "Lisbon": "March 2022", "Friends": ""},
+		},
+		{
+			name:   "year",
+			group:  DateGroupYear,
+			albums: []string{"2021", "Lisbon (2022)", "Beach (2023)", "Beach (2024)", "1000 Islands", "Friends"},
+			dates: map[string]string{
+				"Beach (2023)":  "August 2023",
+				"Beach (2024)":  "July 2024",
+				"Lisbon (2022)": "March 2022",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, err := newAlbumNamer(&AutoCreateAlbumsOptions{
+				Dates: &FolderDateOptions{Description: "January 2006", Group: tt.group},
+			})
+			if err != nil {
+				t.Fatalf("expected nil, got %v", err)
+			}
+
+			groups := map[string][]string{}
+
+			for _, p := range paths {
+				segments := strings.Split(p, "/")[1:]
+
+				name, err := n.name(newNameData(p, segments, len(segments)-1, 0))
+				if err != nil {
+					t.Fatalf("expected nil, got %v", err)
+				}
+
+				groups[name] = append(groups[name], p)
+			}
+
+			if got := n.sorted(groups); !reflect.DeepEqual(got, tt.albums) {
+				t.Errorf("unexpected albums: %v (expected %v)", got, tt.albums)
+			}
+
+			for name, want := range tt.dates {
+				if got := n.description(name); got != want {
+					t.Errorf("unexpected description of %s: %q (expected %q)", name, got, want)
+				}
+			}
+		})
+	}
+
+	t.Run("invalid options", func(t *testing.T) {
+		_, err := newAlbumNamer(&AutoCreateAlbumsOptions{
+			Dates: &FolderDateOptions{Group: "decade"},
+		})
+		if err == nil {
+			t.Error("expected an error, got nil")
+		}
+	})
+}
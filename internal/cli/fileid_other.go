@@ -0,0 +1,31 @@
+//go:build !windows
+
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package cli
+
+import (
+	"io/fs"
+	"syscall"
+)
+
+// fileID identifies a folder by device and inode.
+type fileID struct {
+	dev  uint64
+	ino  uint64
+	path string
+}
+
+// newFileID returns the folder identifier, the path is used when the device
+// and inode are not available.
+func newFileID(path string, info fs.FileInfo) fileID {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return fileID{path: path}
+	}
+
+	return fileID{dev: uint64(st.Dev), ino: st.Ino} //nolint:unconvert
+}
@@ -0,0 +1,24 @@
+//go:build windows
+
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package cli
+
+import (
+	"io/fs"
+	"strings"
+)
+
+// fileID identifies a folder by its real path, the file index is not
+// available from the file info on Windows.
+type fileID struct {
+	path string
+}
+
+// newFileID returns the folder identifier.
+func newFileID(path string, _ fs.FileInfo) fileID {
+	return fileID{path: strings.ToLower(path)}
+}
@@ -0,0 +1,449 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package cli
+
+import (
+	"context"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/faabiosr/imt/internal/client"
+	"github.com/faabiosr/imt/internal/errors"
+)
+
+// listFolders returns the folder paths as seen by Immich, from the local
+// folder tree or from the server when remote is set. Excluded folders are
+// removed from the list.
+func listFolders(ctx context.Context, cl *client.Client, opts *AutoCreateAlbumsOptions) ([]string, error) {
+	tree, err := listFolderTree(ctx, cl, opts)
+
+	return tree.paths, err
+}
+
+// folderTree holds the folder paths listed, and the local folders walked
+// mapped to their paths as seen by Immich, excluded ones included. Folders
+// above the mapped local paths are mapped to an empty path.
+type folderTree struct {
+	paths []string
+	dirs  map[string]string
+}
+
+// listFolderTree lists the folders like listFolders, keeping the local
+// folders walked.
+func listFolderTree(ctx context.Context, cl *client.Client, opts *AutoCreateAlbumsOptions) (*folderTree, error) {
+	tree := &folderTree{dirs: map[string]string{}}
+
+	rel, err := folderRel(opts)
+	if err != nil {
+		return tree, err
+	}
+
+	excludes, err := excludeFilter(opts.Exclude, opts.excludeGlobs(), rel)
+	if err != nil {
+		return tree, err
+	}
+
+	includes, err := includeFilter(opts.Include, globOptions{legacy: opts.LegacyGlobs}, rel)
+	if err != nil {
+		return tree, err
+	}
+
+	if opts.Remote {
+		tree.paths, err = fetchFolders(ctx, cl, opts, excludes)
+	} else {
+		tree.paths, tree.dirs, err = walkFolders(opts, excludes)
+	}
+
+	tree.paths = slices.DeleteFunc(tree.paths, func(p string) bool { return !includes(p) })
+
+	return tree, err
+}
+
+// includeFilter apply a glob/regexp filter to keep only the folders matching,
+// all the folders are kept without patterns.
+func includeFilter(includes []string, g globOptions, rel func(path string) string) (func(path string) bool, error) {
+	fn := func(string) bool { return true }
+
+	if len(includes) == 0 {
+		return fn, nil
+	}
+
+	rules, err := newExcludeRules(includes, g, false)
+	if err != nil {
+		return fn, err
+	}
+
+	return func(path string) bool {
+		return rules.match(path, rel(path), false)
+	}, nil
+}
+
+// folderRel returns a function converting the paths as seen by Immich into
+// paths relative to the folder root, empty for paths outside of it. Local
+// paths are found by reversing the path mappings.
+func folderRel(opts *AutoCreateAlbumsOptions) (func(path string) string, error) {
+	root := filepath.Dir(opts.Folder)
+
+	if opts.Remote {
+		return func(path string) string {
+			rel, _ := cutPathPrefix(path, root)
+			return rel
+		}, nil
+	}
+
+	mapper, err := newPathMapper(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(path string) string {
+		local, ok := mapper.local(path)
+		if !ok {
+			return ""
+		}
+
+		rel, _ := cutPathPrefix(local, root)
+
+		return rel
+	}, nil
+}
+
+// walkFolders walks the local folder tree, replacing the local paths by the
+// paths as seen by Immich. The local folders walked are returned mapped to
+// their paths as seen by Immich.
+func walkFolders(opts *AutoCreateAlbumsOptions, excludes func(string) bool) ([]string, map[string]string, error) {
+	folder := filepath.Dir(opts.Folder)
+
+	mapper, err := newPathMapper(opts)
+	if err != nil {
+		return nil, map[string]string{}, err
+	}
+
+	w := &folderWalker{
+		opts:     opts,
+		excludes: excludes,
+		mapper:   mapper,
+		ignores:  newIgnoreFiles(opts.excludeGlobs()),
+		depth:    strings.Count(folder, string(os.PathSeparator)),
+		visited:  map[fileID]bool{},
+		seen:     map[string]bool{},
+		dirs:     map[string]string{},
+		paths:    []string{},
+	}
+
+	err = w.walk(folder, folder)
+
+	return w.paths, w.dirs, err
+}
+
+// folderWalker walks a local folder tree, optionally following the symbolic
+// links to folders.
+type folderWalker struct {
+	opts     *AutoCreateAlbumsOptions
+	excludes func(string) bool
+	mapper   *pathMapper
+	ignores  *ignoreFiles
+	depth    int
+	visited  map[fileID]bool
+	seen     map[string]bool
+	dirs     map[string]string
+	paths    []string
+}
+
+// walk walks the folder root, which is found at the logical path when
+// reached through a symbolic link. The folder depth, exclude patterns and
+// ignore files are evaluated against the logical paths.
+func (w *folderWalker) walk(root, logical string) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		lpath := logical + strings.TrimPrefix(path, root)
+
+		if !w.opts.Recursive && strings.Count(lpath, string(os.PathSeparator)) > w.depth+1 {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+
+			return nil
+		}
+
+		if w.opts.FollowSymlinks && d.Type()&fs.ModeSymlink != 0 {
+			return w.follow(path, lpath)
+		}
+
+		if !d.IsDir() {
+			return nil
+		}
+
+		if w.opts.FollowSymlinks {
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+
+			w.visited[newFileID(path, info)] = true
+		}
+
+		return w.add(path, lpath)
+	})
+}
+
+// follow walks the folder the symbolic link points to, unless the folder was
+// already walked, which also prevents loops.
+func (w *folderWalker) follow(path, lpath string) error {
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		// broken links are ignored like any other file.
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if !info.IsDir() {
+		return nil
+	}
+
+	target, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return err
+	}
+
+	id := newFileID(target, info)
+	if w.visited[id] {
+		return nil
+	}
+
+	w.visited[id] = true
+
+	return w.walk(target, lpath)
+}
+
+// add appends the folder to the list. The server path is mapped from the real
+// path of the folder, falling back to the logical path when the real path is
+// not covered by any path mapping. Folders above the mapped local paths are
+// walked without being listed.
+func (w *folderWalker) add(path, lpath string) error {
+	server, err := w.mapper.server(path)
+	if err != nil && path != lpath {
+		server, err = w.mapper.server(lpath)
+	}
+
+	covered := err == nil
+	if !covered && !w.mapper.parent(path) && !w.mapper.parent(lpath) {
+		return err
+	}
+
+	excluded := covered && w.excludes(server)
+
+	if !w.opts.NoIgnoreFiles {
+		// ignore files override the exclude patterns, and their rules
+		// apply to the folder children, not to the folder itself.
+		excluded = w.ignores.match(lpath, excluded)
+
+		if err := w.ignores.load(lpath); err != nil {
+			return err
+		}
+	}
+
+	// folders only walked have no server path, but are still watched.
+	w.dirs[path] = server
+
+	if !covered {
+		return nil
+	}
+
+	if !excluded && !w.seen[server] {
+		w.seen[server] = true
+		w.paths = append(w.paths, server)
+	}
+
+	return nil
+}
+
+// fetchFolders builds the folder tree from the folders known by the server,
+// the folder option is the path as seen by Immich. Like a local walk, the
+// tree holds the root and the folders between the root and the folders with
+// assets, sorted like a folder tree walk.
+func fetchFolders(ctx context.Context, cl *client.Client, opts *AutoCreateAlbumsOptions, excludes func(string) bool) ([]string, error) {
+	unique, err := fetchUniquePaths(ctx, cl)
+	if err != nil {
+		return nil, err
+	}
+
+	root := filepath.Clean(filepath.Dir(opts.Folder))
+
+	paths := []string{}
+
+	for _, path := range unique {
+		rel, ok := cutPathPrefix(filepath.Clean(path), root)
+		if !ok {
+			continue
+		}
+
+		segments := strings.Split(rel, string(os.PathSeparator))[1:]
+		if !opts.Recursive && len(segments) > 1 {
+			segments = segments[:1]
+		}
+
+		path = root
+		paths = append(paths, path)
+
+		for _, s := range segments {
+			path = filepath.Join(path, s)
+			paths = append(paths, path)
+		}
+	}
+
+	slices.SortFunc(paths, func(a, b string) int {
+		return slices.Compare(
+			strings.Split(a, string(os.PathSeparator)),
+			strings.Split(b, string(os.PathSeparator)),
+		)
+	})
+
+	paths = slices.Compact(paths)
+
+	return slices.DeleteFunc(paths, excludes), nil
+}
+
+// fetchUniquePaths returns the unique folder paths of the assets stored.
+func fetchUniquePaths(ctx context.Context, cl *client.Client) ([]string, error) {
+	resource, _ := url.Parse("/api/view/folder/unique-paths")
+
+	var paths []string
+
+	req, err := cl.NewRequest(ctx, http.MethodGet, resource, nil)
+	if err != nil {
+		return paths, err
+	}
+
+	return paths, cl.Do(req, &paths)
+}
+
+// PathMapping represents a local path prefix and the path prefix where the
+// same folder is seen by Immich.
+type PathMapping struct {
+	Local  string `json:"local"`
+	Server string `json:"server"`
+}
+
+// pathMapper translates local paths into paths as seen by Immich.
+type pathMapper struct {
+	mappings []PathMapping
+}
+
+// newPathMapper creates a path mapper from the path mappings and the original
+// path options. Without any of them, paths are relative to the folder.
+func newPathMapper(opts *AutoCreateAlbumsOptions) (*pathMapper, error) {
+	m, err := newPathMappings(opts.PathMappings)
+	if err != nil {
+		return nil, err
+	}
+
+	folder := filepath.Dir(opts.Folder)
+
+	switch {
+	case opts.OriginalPath != "":
+		m.mappings = append(m.mappings, PathMapping{Local: folder, Server: filepath.Dir(opts.OriginalPath)})
+	case len(m.mappings) == 0:
+		m.mappings = append(m.mappings, PathMapping{Local: folder})
+	}
+
+	m.sort()
+
+	return m, nil
+}
+
+// newPathMappings creates a path mapper from the path mappings only.
+func newPathMappings(mappings []PathMapping) (*pathMapper, error) {
+	m := &pathMapper{}
+
+	for i, pm := range mappings {
+		if pm.Local == "" || pm.Server == "" {
+			return nil, errors.Errorf("path mapping #%d must have both local and server paths", i+1)
+		}
+
+		local := filepath.Clean(pm.Local)
+
+		if slices.ContainsFunc(m.mappings, func(p PathMapping) bool { return p.Local == local }) {
+			return nil, errors.Errorf("duplicate path mapping for local path %q", local)
+		}
+
+		m.mappings = append(m.mappings, PathMapping{Local: local, Server: filepath.Clean(pm.Server)})
+	}
+
+	m.sort()
+
+	return m, nil
+}
+
+// sort puts the longest local prefixes first.
+func (m *pathMapper) sort() {
+	slices.SortStableFunc(m.mappings, func(a, b PathMapping) int {
+		return len(b.Local) - len(a.Local)
+	})
+}
+
+// server returns the path as seen by Immich using the longest local prefix
+// that matches the path.
+func (m *pathMapper) server(path string) (string, error) {
+	for _, pm := range m.mappings {
+		if rest, ok := cutPathPrefix(path, pm.Local); ok {
+			return strings.TrimSuffix(pm.Server, string(os.PathSeparator)) + rest, nil
+		}
+	}
+
+	return "", errors.Errorf("folder %q is not covered by any path mapping", path)
+}
+
+// local returns the local path of a path as seen by Immich, using the longest
+// server prefix that matches the path.
+func (m *pathMapper) local(path string) (string, bool) {
+	mappings := slices.Clone(m.mappings)
+	slices.SortStableFunc(mappings, func(a, b PathMapping) int {
+		return len(b.Server) - len(a.Server)
+	})
+
+	for _, pm := range mappings {
+		if rest, ok := cutPathPrefix(path, pm.Server); ok {
+			return strings.TrimSuffix(pm.Local, string(os.PathSeparator)) + rest, true
+		}
+	}
+
+	return "", false
+}
+
+// parent reports whether the path is a parent folder of a mapped local path.
+func (m *pathMapper) parent(path string) bool {
+	return slices.ContainsFunc(m.mappings, func(pm PathMapping) bool {
+		rest, ok := cutPathPrefix(pm.Local, path)
+		return ok && rest != ""
+	})
+}
+
+// cutPathPrefix returns the path without the prefix, the prefix must match
+// whole path segments.
+func cutPathPrefix(path, prefix string) (string, bool) {
+	if prefix == string(os.PathSeparator) {
+		return path, strings.HasPrefix(path, prefix)
+	}
+
+	rest, ok := strings.CutPrefix(path, prefix)
+	if !ok || (rest != "" && !strings.HasPrefix(rest, string(os.PathSeparator))) {
+		return "", false
+	}
+
+	return rest, true
+}
@@ -0,0 +1,329 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+
+	"github.com/faabiosr/imt/internal/client"
+)
+
+func TestFolder_fetchFolders(t *testing.T) {
+	t.Run("failure", func(t *testing.T) {
+		ctx := context.Background()
+		hc := http.DefaultClient
+
+		httpmock.ActivateNonDefault(hc)
+		defer httpmock.DeactivateAndReset()
+
+		httpmock.RegisterResponder(
+			http.MethodGet,
+			testHost+"/api/view/folder/unique-paths",
+			httpmock.NewJsonResponderOrPanic(
+				http.StatusInternalServerError,
+				json.RawMessage(`{"message": "failed"}`),
+			),
+		)
+
+		baseURL, _ := url.Parse(testHost)
+		cl := client.NewWithHTTPClient(baseURL, testAPIKey, hc)
+
+		opts := &AutoCreateAlbumsOptions{Folder: "/external/", Remote: true}
+
+		if _, err := listFolders(ctx, cl, opts); err == nil {
+			t.Error("expected an error, got nil")
+		}
+	})
+
+	tests := []struct {
+		name string
+		opts *AutoCreateAlbumsOptions
+		want []string
+	}{
+		{
+			name: "not recursive",
+			opts: &AutoCreateAlbumsOptions{Folder: "/external/2023/"},
+			want: []string{"/external/2023", "/external/2023/Beach", "/external/2023/Lisbon"},
+		},
+		{
+			name: "recursive",
+			opts: &AutoCreateAlbumsOptions{Folder: "/external/2023/", Recursive: true},
+			want: []string{
+				"/external/2023",
+				"/external/2023/Beach",
+				"/external/2023/Lisbon",
+				"/external/2023/Lisbon/day 1",
+				"/external/2023/Lisbon/day2",
+				"/external/2023/Lisbon/day2/food",
+			},
+		},
+		{
+			name: "exclude",
+			opts: &AutoCreateAlbumsOptions{Folder: "/external/2023/", Recursive: true, Exclude: []string{"/Lisbon/*"}},
+			want: []string{"/external/2023", "/external/2023/Beach", "/external/2023/Lisbon"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			hc := http.DefaultClient
+
+			httpmock.ActivateNonDefault(hc)
+			defer httpmock.DeactivateAndReset()
+
+			httpmock.RegisterResponder(
+				http.MethodGet,
+				testHost+"/api/view/folder/unique-paths",
+				httpmock.NewJsonResponderOrPanic(
+					http.StatusOK,
+					json.RawMessage(`[
+						"/external/2023/Lisbon/day2/food",
+						"/external/2023/Lisbon/day2",
+						"/external/2023/Lisbon/day 1",
+						"/external/2023/Beach/",
+						"/external/2023/Beach",
+						"/external/2023/Lisbon",
+						"/external/2023",
+						"/external/2022/Beach",
+						"/other/2023/Beach"
+					]`),
+				),
+			)
+
+			baseURL, _ := url.Parse(testHost)
+			cl := client.NewWithHTTPClient(baseURL, testAPIKey, hc)
+
+			tt.opts.Remote = true
+
+			paths, err := listFolders(ctx, cl, tt.opts)
+			if err != nil {
+				t.Errorf("expected nil, got %v", err)
+			}
+
+			if !reflect.DeepEqual(paths, tt.want) {
+				t.Errorf("unexpected paths: %v (expected %v)", paths, tt.want)
+			}
+		})
+	}
+}
+
+func TestFolder_listFoldersRemoteMatchesLocal(t *testing.T) {
+	tmp := t.TempDir()
+
+	for _, dir := range []string{"Beach", "Lisbon/day2/food"} {
+		if err := os.MkdirAll(filepath.Join(tmp, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	hc := http.DefaultClient
+
+	httpmock.ActivateNonDefault(hc)
+	defer httpmock.DeactivateAndReset()
+
+	// only the folders holding assets are known by the server.
+	httpmock.RegisterResponder(
+		http.MethodGet,
+		testHost+"/api/view/folder/unique-paths",
+		httpmock.NewJsonResponderOrPanic(
+			http.StatusOK,
+			json.RawMessage(`["/external/2023/Beach", "/external/2023/Lisbon/day2", "/external/2023/Lisbon/day2/food"]`),
+		),
+	)
+
+	baseURL, _ := url.Parse(testHost)
+	cl := client.NewWithHTTPClient(baseURL, testAPIKey, hc)
+
+	for _, recursive := range []bool{false, true} {
+		local := &AutoCreateAlbumsOptions{Folder: tmp + "/", OriginalPath: "/external/2023/", Recursive: recursive}
+		remote := &AutoCreateAlbumsOptions{Folder: "/external/2023/", Remote: true, Recursive: recursive}
+
+		want, err := listFolders(context.Background(), nil, local)
+		if err != nil {
+			t.Fatalf("expected nil, got %v", err)
+		}
+
+		paths, err := listFolders(context.Background(), cl, remote)
+		if err != nil {
+			t.Fatalf("expected nil, got %v", err)
+		}
+
+		if !reflect.DeepEqual(paths, want) {
+			t.Errorf("unexpected remote paths (recursive %v): %v (expected %v)", recursive, paths, want)
+		}
+	}
+}
+
+func TestFolder_pathMapper(t *testing.T) {
+	t.Run("invalid mappings", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			mappings []PathMapping
+		}{
+			{"empty local", []PathMapping{{Server: "/external"}}},
+			{"empty server", []PathMapping{{Local: "/nas"}}},
+			{"duplicate", []PathMapping{{Local: "/nas/", Server: "/a"}, {Local: "/nas", Server: "/b"}}},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				_, err := newPathMapper(&AutoCreateAlbumsOptions{PathMappings: tt.mappings})
+				if err == nil {
+					t.Error("expected an error, got nil")
+				}
+			})
+		}
+	})
+
+	opts := &AutoCreateAlbumsOptions{
+		Folder:       "/mnt/photos/",
+		OriginalPath: "/usr/src/app/photos/",
+		PathMappings: []PathMapping{
+			{Local: "/mnt/nas1", Server: "/libraries/one"},
+			{Local: "/mnt/nas1/family/", Server: "/libraries/family"},
+			{Local: "/mnt/nas2", Server: "/"},
+		},
+	}
+
+	tests := []struct {
+		path string
+		want string
+		err  bool
+	}{
+		{"/mnt/nas1", "/libraries/one", false},
+		{"/mnt/nas1/2023/Beach", "/libraries/one/2023/Beach", false},
+		{"/mnt/nas1/family/Ana", "/libraries/family/Ana", false},
+		{"/mnt/nas1/familyfriends", "/libraries/one/familyfriends", false},
+		{"/mnt/nas2/Trip", "/Trip", false},
+		{"/mnt/photos/2025", "/usr/src/app/photos/2025", false},
+		{"/mnt/nas3/Trip", "", true},
+		{"/mnt/nas10/Trip", "", true},
+	}
+
+	m, err := newPathMapper(opts)
+	if err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := m.server(tt.path)
+			if (err != nil) != tt.err {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("unexpected path: %s (expected %s)", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFolder_walkFoldersSymlinks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symbolic links require privileges on windows")
+	}
+
+	tmp, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+
+	for _, dir := range []string{"/photos/2024/Beach", "/disks/d2/2023/Trip"} {
+		if err := os.MkdirAll(tmp+dir, 0o755); err != nil {
+			t.Fatalf("expected nil, got %v", err)
+		}
+	}
+
+	links := map[string]string{
+		"/photos/2023":      tmp + "/disks/d2/2023",
+		"/photos/2024/loop": tmp + "/photos",
+		"/photos/2024/same": tmp + "/photos/2024/Beach",
+		"/photos/broken":    tmp + "/missing",
+	}
+
+	for name, target := range links {
+		if err := os.Symlink(target, tmp+name); err != nil {
+			t.Fatalf("expected nil, got %v", err)
+		}
+	}
+
+	mappings := []PathMapping{
+		{Local: tmp + "/photos", Server: "/library/photos"},
+		{Local: tmp + "/disks", Server: "/library/disks"},
+	}
+
+	tests := []struct {
+		name      string
+		follow    bool
+		recursive bool
+		mappings  []PathMapping
+		want      []string
+	}{
+		{
+			name:      "not following",
+			recursive: true,
+			mappings:  mappings,
+			want:      []string{"/library/photos", "/library/photos/2024", "/library/photos/2024/Beach"},
+		},
+		{
+			name:      "following",
+			follow:    true,
+			recursive: true,
+			mappings:  mappings,
+			want: []string{
+				"/library/photos",
+				"/library/disks/d2/2023",
+				"/library/disks/d2/2023/Trip",
+				"/library/photos/2024",
+				"/library/photos/2024/Beach",
+			},
+		},
+		{
+			name:     "following not recursive",
+			follow:   true,
+			mappings: mappings,
+			want:     []string{"/library/photos", "/library/disks/d2/2023", "/library/photos/2024"},
+		},
+		{
+			name:      "real path not mapped",
+			follow:    true,
+			recursive: true,
+			want:      []string{"", "/2023", "/2023/Trip", "/2024", "/2024/Beach"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := &AutoCreateAlbumsOptions{
+				Folder:         tmp + "/photos/",
+				Recursive:      tt.recursive,
+				PathMappings:   tt.mappings,
+				FollowSymlinks: tt.follow,
+			}
+
+			paths, err := listFolders(context.Background(), nil, opts)
+			if err != nil {
+				t.Errorf("expected nil, got %v", err)
+			}
+
+			if !reflect.DeepEqual(paths, tt.want) {
+				t.Errorf("unexpected paths: %v (expected %v)", paths, tt.want)
+			}
+		})
+	}
+}
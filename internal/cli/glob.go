@@ -9,21 +9,44 @@ import (
 	"bytes"
 	"fmt"
 	"regexp"
+	"strings"
 )
 
 // globToRegexp converts an rsync style glob to a regexp.
 //
+// By default "*" and "?" do not match path separators, "**" matches any
+// number of path segments and the pattern must match whole path segments,
+// so the folders inside a matched folder also match. In legacy mode "*"
+// matches anything, "**" is not allowed and the pattern can match any part
+// of the path.
+//
 // Code extracted from rclone: https://github.com/rclone/rclone
 //
 // nolint:gocyclo
-func globToRegexp(glob string) (*regexp.Regexp, error) {
+func globToRegexp(glob string, legacy bool) (*regexp.Regexp, error) {
+	if !legacy && len(glob) > 1 {
+		// all the paths are folders, a trailing slash changes nothing.
+		glob = strings.TrimSuffix(glob, "/")
+	}
+
 	var re bytes.Buffer
 	consecutiveStars := 0
-	insertStars := func() error {
+	swallowSlash := false
+	insertStars := func(next rune) error {
 		if consecutiveStars > 0 {
-			switch consecutiveStars {
-			case 1:
+			switch {
+			case consecutiveStars == 1 && legacy:
 				_, _ = re.WriteString(`.*`)
+			case consecutiveStars == 1:
+				_, _ = re.WriteString(`[^/]*`)
+			case consecutiveStars == 2 && !legacy:
+				// "**/" matches zero or more folders.
+				if (re.Len() == 0 || bytes.HasSuffix(re.Bytes(), []byte("/"))) && next == '/' {
+					_, _ = re.WriteString(`(.*/)?`)
+					swallowSlash = true
+				} else {
+					_, _ = re.WriteString(`.*`)
+				}
 			default:
 				return fmt.Errorf("too many stars in %q", glob)
 			}
@@ -75,11 +98,15 @@ func globToRegexp(glob string) (*regexp.Regexp, error) {
 			continue
 		}
 		if c != '*' {
-			err := insertStars()
+			err := insertStars(c)
 			if err != nil {
 				return nil, err
 			}
 		}
+		if swallowSlash {
+			swallowSlash = false
+			continue
+		}
 		if inBrackets > 0 {
 			_, _ = re.WriteRune(c)
 
@@ -99,7 +126,11 @@ func globToRegexp(glob string) (*regexp.Regexp, error) {
 		case '*':
 			consecutiveStars++
 		case '?':
-			_, _ = re.WriteString(`.`)
+			if legacy {
+				_, _ = re.WriteString(`.`)
+			} else {
+				_, _ = re.WriteString(`[^/]`)
+			}
 		case '[':
 			_, _ = re.WriteRune(c)
 			inBrackets++
@@ -138,7 +169,7 @@ func globToRegexp(glob string) (*regexp.Regexp, error) {
 		}
 	}
 
-	err := insertStars()
+	err := insertStars(0)
 	if err != nil {
 		return nil, err
 	}
@@ -155,6 +186,16 @@ func globToRegexp(glob string) (*regexp.Regexp, error) {
 		return nil, fmt.Errorf("mismatched '{{' and '}}' in glob %q", glob)
 	}
 
+	if !legacy && glob != "" {
+		pattern := re.String()
+		if !strings.HasPrefix(glob, "/") {
+			pattern = `(^|/)` + pattern
+		}
+
+		re.Reset()
+		_, _ = re.WriteString(pattern + `(/|$)`)
+	}
+
 	result, err := regexp.Compile(re.String())
 	if err != nil {
 		return nil, fmt.Errorf("bad glob pattern %q (regexp %q): %w", glob, re.String(), err)
@@ -57,7 +57,52 @@ func Test_globToRegexp(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(fmt.Sprintf("glob %s", tt.glob), func(t *testing.T) {
-			re, actual := globToRegexp(tt.glob)
+			re, actual := globToRegexp(tt.glob, true)
+
+			var err string
+			if actual != nil {
+				err = actual.Error()
+			}
+
+			if !strings.Contains(err, tt.err) {
+				t.Errorf("unexpected error: %s (expected %s)", err, tt.err)
+			}
+
+			if actual != nil {
+				t.SkipNow()
+			}
+
+			if got := re.String(); tt.want != got {
+				t.Errorf("unexpected error: %s (expected %s)", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_globToRegexpSegments(t *testing.T) {
+	tests := []struct {
+		glob string
+		want string
+		err  string
+	}{
+		{``, ``, ``},
+		{`potato`, `(^|/)potato(/|$)`, ``},
+		{`/potato`, `/potato(/|$)`, ``},
+		{`/potato/`, `/potato(/|$)`, ``},
+		{`potato?sausage`, `(^|/)potato[^/]sausage(/|$)`, ``},
+		{`*.jpg`, `(^|/)[^/]*\.jpg(/|$)`, ``},
+		{`potato**`, `(^|/)potato.*(/|$)`, ``},
+		{`**/potato`, `(^|/)(.*/)?potato(/|$)`, ``},
+		{`/a/**/b`, `/a/(.*/)?b(/|$)`, ``},
+		{`/a/**`, `/a/.*(/|$)`, ``},
+		{`a**b`, `(^|/)a.*b(/|$)`, ``},
+		{`***`, ``, `too many stars`},
+		{`/{{\d{4}}}/*`, `/(\d{4})/[^/]*(/|$)`, ``},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("glob %s", tt.glob), func(t *testing.T) {
+			re, actual := globToRegexp(tt.glob, false)
 
 			var err string
 			if actual != nil {
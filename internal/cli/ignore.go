@@ -0,0 +1,111 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/faabiosr/imt/internal/errors"
+)
+
+// ignoreFile is the name of the file holding exclude patterns scoped to the
+// folder where it is stored, like a .gitignore file.
+const ignoreFile = ".imtignore"
+
+// ignoreFiles holds the rules of the ignore files found during a folder walk.
+type ignoreFiles struct {
+	globs globOptions
+	dirs  map[string]excludeRules
+}
+
+// newIgnoreFiles creates the ignore files holder.
+func newIgnoreFiles(globs globOptions) *ignoreFiles {
+	return &ignoreFiles{globs: globs, dirs: map[string]excludeRules{}}
+}
+
+// load reads the ignore file of the folder, if it exists.
+func (f *ignoreFiles) load(dir string) error {
+	name := filepath.Join(dir, ignoreFile)
+
+	content, err := os.ReadFile(filepath.Clean(name))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	if err != nil {
+		return errors.Errorf("unable to read ignore file: %w", err)
+	}
+
+	rules, err := newExcludeRules(parseIgnoreFile(content), f.globs, true)
+	if err != nil {
+		return errors.Errorf("invalid ignore file %s: %w", name, err)
+	}
+
+	f.dirs[dir] = rules
+
+	return nil
+}
+
+// match evaluates the ignore files of the path ancestors, from the top most
+// to the closest one. The patterns are matched against the path relative to
+// the folder of each ignore file.
+func (f *ignoreFiles) match(path string, excluded bool) bool {
+	if len(f.dirs) == 0 {
+		return excluded
+	}
+
+	var ancestors []string
+
+	for dir := filepath.Dir(path); ; dir = filepath.Dir(dir) {
+		ancestors = append(ancestors, dir)
+
+		if parent := filepath.Dir(dir); parent == dir {
+			break
+		}
+	}
+
+	slices.Reverse(ancestors)
+
+	for _, dir := range ancestors {
+		rules, ok := f.dirs[dir]
+		if !ok {
+			continue
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			continue
+		}
+
+		rel = string(os.PathSeparator) + filepath.ToSlash(rel)
+		excluded = rules.match(rel, rel, excluded)
+	}
+
+	return excluded
+}
+
+// parseIgnoreFile returns the patterns of an ignore file, skipping empty lines
+// and comments.
+func parseIgnoreFile(content []byte) []string {
+	var patterns []string
+
+	s := bufio.NewScanner(bytes.NewReader(content))
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		patterns = append(patterns, line)
+	}
+
+	return patterns
+}
@@ -0,0 +1,156 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestIgnore_parseIgnoreFile(t *testing.T) {
+	content := []byte("# screenshots\n\nScreenshots\n  /Raw  \n!/Raw/best\n")
+
+	expected := []string{"Screenshots", "/Raw", "!/Raw/best"}
+
+	if got := parseIgnoreFile(content); !reflect.DeepEqual(got, expected) {
+		t.Errorf("unexpected patterns: %v (expected %v)", got, expected)
+	}
+}
+
+func TestIgnore_walkFolders(t *testing.T) {
+	setup := func(t *testing.T) string {
+		t.Helper()
+
+		tmp := t.TempDir()
+
+		dirs := []string{
+			"/2023/Raw/best",
+			"/2023/Beach/Raw",
+			"/2023/Beach/Screenshots",
+			"/2024/Raw",
+			"/2024/Trip/2023/Raw",
+		}
+
+		for _, dir := range dirs {
+			if err := os.MkdirAll(tmp+dir, 0o755); err != nil {
+				t.Fatalf("expected nil, got %v", err)
+			}
+		}
+
+		files := map[string]string{
+			"/" + ignoreFile:               "Screenshots\n",
+			"/2023/" + ignoreFile:          "# raw files only at this level\n/Raw\n!/Raw/best\n",
+			"/2024/Trip/" + ignoreFile:     "2023/Raw\n",
+			"/2023/Beach/" + ignoreFile:    "!Screenshots\n",
+			"/2023/Raw/best/" + ignoreFile: "",
+		}
+
+		for name, content := range files {
+			if err := os.WriteFile(filepath.Join(tmp, name), []byte(content), 0o600); err != nil {
+				t.Fatalf("expected nil, got %v", err)
+			}
+		}
+
+		return tmp
+	}
+
+	t.Run("ignore files", func(t *testing.T) {
+		tmp := setup(t)
+
+		opts := &AutoCreateAlbumsOptions{
+			Folder:    tmp + string(os.PathSeparator),
+			Recursive: true,
+			Exclude:   []string{"/2024/Raw"},
+		}
+
+		paths, err := listFolders(context.Background(), nil, opts)
+		if err != nil {
+			t.Errorf("expected nil, got %v", err)
+		}
+
+		expected := []string{
+			"",
+			"/2023",
+			"/2023/Beach",
+			"/2023/Beach/Raw",
+			"/2023/Beach/Screenshots",
+			"/2023/Raw/best",
+			"/2024",
+			"/2024/Trip",
+			"/2024/Trip/2023",
+		}
+
+		if !reflect.DeepEqual(paths, expected) {
+			t.Errorf("unexpected paths: %v (expected %v)", paths, expected)
+		}
+	})
+
+	t.Run("no ignore files", func(t *testing.T) {
+		tmp := setup(t)
+
+		opts := &AutoCreateAlbumsOptions{
+			Folder:        tmp + string(os.PathSeparator),
+			Recursive:     true,
+			NoIgnoreFiles: true,
+		}
+
+		paths, err := listFolders(context.Background(), nil, opts)
+		if err != nil {
+			t.Errorf("expected nil, got %v", err)
+		}
+
+		if n := len(paths); n != 12 {
+			t.Errorf("unexpected number of paths: %d (expected %d)", n, 12)
+		}
+	})
+
+	t.Run("ignore case", func(t *testing.T) {
+		tmp := setup(t)
+
+		opts := &AutoCreateAlbumsOptions{
+			Folder:            tmp + string(os.PathSeparator),
+			Recursive:         true,
+			NoIgnoreFiles:     true,
+			Exclude:           []string{"raw"},
+			ExcludeIgnoreCase: true,
+		}
+
+		paths, err := listFolders(context.Background(), nil, opts)
+		if err != nil {
+			t.Errorf("expected nil, got %v", err)
+		}
+
+		expected := []string{
+			"",
+			"/2023",
+			"/2023/Beach",
+			"/2023/Beach/Screenshots",
+			"/2024",
+			"/2024/Trip",
+			"/2024/Trip/2023",
+		}
+
+		if !reflect.DeepEqual(paths, expected) {
+			t.Errorf("unexpected paths: %v (expected %v)", paths, expected)
+		}
+	})
+
+	t.Run("invalid pattern", func(t *testing.T) {
+		tmp := t.TempDir()
+		if err := os.WriteFile(filepath.Join(tmp, ignoreFile), []byte("***\n"), 0o600); err != nil {
+			t.Fatalf("expected nil, got %v", err)
+		}
+
+		opts := &AutoCreateAlbumsOptions{Folder: tmp + string(os.PathSeparator)}
+
+		if _, err := listFolders(context.Background(), nil, opts); err == nil {
+			t.Error("expected an error, got nil")
+		}
+	})
+}
@@ -0,0 +1,163 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/faabiosr/imt/internal/client"
+	"github.com/faabiosr/imt/internal/errors"
+)
+
+// sharedLinkAlbum is the type of the shared links of albums.
+const sharedLinkAlbum = "ALBUM"
+
+// SharedLink represents a public link to an album or assets.
+type SharedLink struct {
+	ID            string     `json:"id"`
+	Key           string     `json:"key"`
+	Type          string     `json:"type"`
+	Description   string     `json:"description,omitempty"`
+	ExpiresAt     *time.Time `json:"expiresAt"`
+	AllowDownload bool       `json:"allowDownload"`
+	AllowUpload   bool       `json:"allowUpload"`
+	Password      string     `json:"password,omitempty"`
+	Album         *Album     `json:"album,omitempty"`
+}
+
+// CreateSharedLinkOptions handles the options to create a shared link of an
+// album, referenced by name or ID. Links without expiration never expire.
+type CreateSharedLinkOptions struct {
+	Album         string
+	ExpiresAt     *time.Time
+	Password      string
+	AllowDownload bool
+	AllowUpload   bool
+}
+
+// ParseLinkExpiration parses the expiration of a shared link as a number of
+// days (7d) or a Go duration (12h).
+func ParseLinkExpiration(value string) (time.Duration, error) {
+	var (
+		d   time.Duration
+		err error
+	)
+
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		var n int
+		n, err = strconv.Atoi(days)
+		d = time.Duration(n) * 24 * time.Hour
+	} else {
+		d, err = time.ParseDuration(value)
+	}
+
+	if err != nil || d <= 0 {
+		return 0, errors.Errorf("invalid expiration %q, must be a number of days (7d) or a duration (12h)", value)
+	}
+
+	return d, nil
+}
+
+// CreateSharedLink creates a shared link of an album.
+func CreateSharedLink(ctx context.Context, cl *client.Client, opts *CreateSharedLinkOptions) (*SharedLink, error) {
+	as, err := FetchAlbums(ctx, cl)
+	if err != nil {
+		return nil, err
+	}
+
+	album, err := as.find(opts.Album)
+	if err != nil {
+		return nil, err
+	}
+
+	body := map[string]any{
+		"type":          sharedLinkAlbum,
+		"albumId":       album.ID,
+		"allowDownload": opts.AllowDownload,
+		"allowUpload":   opts.AllowUpload,
+	}
+
+	if opts.ExpiresAt != nil {
+		body["expiresAt"] = opts.ExpiresAt.UTC().Format(time.RFC3339)
+	}
+
+	if opts.Password != "" {
+		body["password"] = opts.Password
+	}
+
+	resource, _ := url.Parse("/api/shared-links")
+
+	req, err := cl.NewRequest(ctx, http.MethodPost, resource, body)
+	if err != nil {
+		return nil, err
+	}
+
+	link := &SharedLink{}
+	if err := cl.Do(req, link); err != nil {
+		return nil, err
+	}
+
+	return link, nil
+}
+
+// FetchSharedLinks returns the shared links of the user.
+func FetchSharedLinks(ctx context.Context, cl *client.Client) ([]SharedLink, error) {
+	resource, _ := url.Parse("/api/shared-links")
+
+	links := []SharedLink{}
+
+	req, err := cl.NewRequest(ctx, http.MethodGet, resource, nil)
+	if err != nil {
+		return links, err
+	}
+
+	return links, cl.Do(req, &links)
+}
+
+// RevokeSharedLinks deletes the shared links of the IDs, the links no longer
+// give access to the albums.
+func RevokeSharedLinks(ctx context.Context, cl *client.Client, ids []string) error {
+	if len(ids) == 0 {
+		return errors.New("at least one shared link is required")
+	}
+
+	var errs []error
+
+	for _, id := range ids {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if err := deleteSharedLink(ctx, cl, id); err != nil {
+			errs = append(errs, errors.Errorf("shared link %q: %w", id, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// deleteSharedLink deletes a shared link.
+func deleteSharedLink(ctx context.Context, cl *client.Client, id string) error {
+	resource, _ := url.Parse(fmt.Sprintf("/api/shared-links/%s", id))
+
+	req, err := cl.NewRequest(ctx, http.MethodDelete, resource, nil)
+	if err != nil {
+		return err
+	}
+
+	return cl.Do(req, nil)
+}
+
+// SharedLinkURL returns the public URL of a shared link on the host.
+func SharedLinkURL(host, key string) (string, error) {
+	return url.JoinPath(host, "share", key)
+}
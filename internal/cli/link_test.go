@@ -0,0 +1,191 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+
+	"github.com/faabiosr/imt/internal/client"
+)
+
+func TestLink_ParseLinkExpiration(t *testing.T) {
+	valid := map[string]time.Duration{
+		"7d":  7 * 24 * time.Hour,
+		"12h": 12 * time.Hour,
+		"90m": 90 * time.Minute,
+	}
+
+	for value, expected := range valid {
+		d, err := ParseLinkExpiration(value)
+		if err != nil {
+			t.Errorf("expected nil for %q, got %v", value, err)
+		}
+
+		if d != expected {
+			t.Errorf("unexpected expiration of %q: %v (expected %v)", value, d, expected)
+		}
+	}
+
+	for _, value := range []string{"", "d", "0d", "-1h", "week"} {
+		if _, err := ParseLinkExpiration(value); err == nil {
+			t.Errorf("expected an error for %q, got nil", value)
+		}
+	}
+}
+
+func TestLink_CreateSharedLink(t *testing.T) {
+	hc := http.DefaultClient
+
+	httpmock.ActivateNonDefault(hc)
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder(
+		http.MethodGet,
+		testHost+"/api/albums",
+		httpmock.NewStringResponder(http.StatusOK, `[{"id": "a1", "albumName": "Wedding"}]`),
+	)
+
+	var body map[string]any
+
+	httpmock.RegisterResponder(
+		http.MethodPost,
+		testHost+"/api/shared-links",
+		func(req *http.Request) (*http.Response, error) {
+			if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+				return nil, err
+			}
+
+			return httpmock.NewStringResponse(http.StatusCreated, `{"id": "l1", "key": "abc", "type": "ALBUM"}`), nil
+		},
+	)
+
+	baseURL, _ := url.Parse(testHost)
+	cl := client.NewWithHTTPClient(baseURL, testAPIKey, hc)
+
+	t.Run("album not found", func(t *testing.T) {
+		if _, err := CreateSharedLink(context.Background(), cl, &CreateSharedLinkOptions{Album: "Birthday"}); err == nil {
+			t.Error("expected an error, got nil")
+		}
+	})
+
+	t.Run("success", func(t *testing.T) {
+		expiresAt := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+
+		opts := &CreateSharedLinkOptions{
+			Album:         "Wedding",
+			ExpiresAt:     &expiresAt,
+			Password:      "secret",
+			AllowDownload: true,
+		}
+
+		link, err := CreateSharedLink(context.Background(), cl, opts)
+		if err != nil {
+			t.Fatalf("expected nil, got %v", err)
+		}
+
+		if link.Key != "abc" {
+			t.Errorf("unexpected link key: %s (expected abc)", link.Key)
+		}
+
+		expected := map[string]any{
+			"type":          "ALBUM",
+			"albumId":       "a1",
+			"expiresAt":     "2025-07-01T12:00:00Z",
+			"password":      "secret",
+			"allowDownload": true,
+			"allowUpload":   false,
+		}
+
+		if !reflect.DeepEqual(body, expected) {
+			t.Errorf("unexpected request: %v (expected %v)", body, expected)
+		}
+	})
+}
+
+func TestLink_FetchSharedLinks(t *testing.T) {
+	hc := http.DefaultClient
+
+	httpmock.ActivateNonDefault(hc)
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder(
+		http.MethodGet,
+		testHost+"/api/shared-links",
+		httpmock.NewStringResponder(http.StatusOK, `[
+			{"id": "l1", "key": "abc", "type": "ALBUM", "expiresAt": "2025-07-01T12:00:00Z", "allowUpload": true, "album": {"id": "a1", "albumName": "Wedding"}},
+			{"id": "l2", "key": "def", "type": "INDIVIDUAL", "expiresAt": null}
+		]`),
+	)
+
+	baseURL, _ := url.Parse(testHost)
+	cl := client.NewWithHTTPClient(baseURL, testAPIKey, hc)
+
+	links, err := FetchSharedLinks(context.Background(), cl)
+	if err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+
+	expiresAt := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+
+	expected := []SharedLink{
+		{ID: "l1", Key: "abc", Type: "ALBUM", ExpiresAt: &expiresAt, AllowUpload: true, Album: &Album{ID: "a1", Name: "Wedding"}},
+		{ID: "l2", Key: "def", Type: "INDIVIDUAL"},
+	}
+
+	if !reflect.DeepEqual(links, expected) {
+		t.Errorf("unexpected links: %+v (expected %+v)", links, expected)
+	}
+}
+
+func TestLink_RevokeSharedLinks(t *testing.T) {
+	hc := http.DefaultClient
+
+	httpmock.ActivateNonDefault(hc)
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder(http.MethodDelete, testHost+"/api/shared-links/l1", httpmock.NewStringResponder(http.StatusOK, ""))
+	httpmock.RegisterResponder(
+		http.MethodDelete,
+		testHost+"/api/shared-links/l2",
+		httpmock.NewJsonResponderOrPanic(http.StatusNotFound, json.RawMessage(`{"message": "not found"}`)),
+	)
+
+	baseURL, _ := url.Parse(testHost)
+	cl := client.NewWithHTTPClient(baseURL, testAPIKey, hc)
+
+	if err := RevokeSharedLinks(context.Background(), cl, nil); err == nil {
+		t.Error("expected an error, got nil")
+	}
+
+	if err := RevokeSharedLinks(context.Background(), cl, []string{"l1", "l2"}); err == nil {
+		t.Error("expected an error, got nil")
+	}
+
+	if n := httpmock.GetTotalCallCount(); n != 2 {
+		t.Errorf("unexpected links revoked: %d (expected 2)", n)
+	}
+}
+
+func TestLink_SharedLinkURL(t *testing.T) {
+	for _, host := range []string{"https://photos.example.com", "https://photos.example.com/"} {
+		u, err := SharedLinkURL(host, "abc")
+		if err != nil {
+			t.Fatalf("expected nil, got %v", err)
+		}
+
+		if expected := "https://photos.example.com/share/abc"; u != expected {
+			t.Errorf("unexpected url: %s (expected %s)", u, expected)
+		}
+	}
+}
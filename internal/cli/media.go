@@ -0,0 +1,70 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package cli
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/faabiosr/imt/internal/errors"
+)
+
+// Media types of the assets.
+const (
+	MediaImage = "image"
+	MediaVideo = "video"
+)
+
+// MediaFilter handles which assets are added to the albums, by media type
+// and file extension. An empty filter matches all the assets.
+type MediaFilter struct {
+	Type       string   `json:"type,omitempty" enum:"image,video"`
+	Extensions []string `json:"extensions,omitempty"`
+}
+
+// validate checks the media filter.
+func (f *MediaFilter) validate() error {
+	if f == nil {
+		return nil
+	}
+
+	switch strings.ToLower(f.Type) {
+	case "", MediaImage, MediaVideo:
+		return nil
+	default:
+		return errors.Errorf("invalid media type %q, must be %s or %s", f.Type, MediaImage, MediaVideo)
+	}
+}
+
+// filter returns the assets matching the media type and extensions.
+func (f *MediaFilter) filter(assets []Asset) []Asset {
+	if f == nil || (f.Type == "" && len(f.Extensions) == 0) {
+		return assets
+	}
+
+	exts := make([]string, 0, len(f.Extensions))
+	for _, e := range f.Extensions {
+		exts = append(exts, "."+strings.ToLower(strings.TrimPrefix(e, ".")))
+	}
+
+	return slices.DeleteFunc(slices.Clone(assets), func(a Asset) bool {
+		if f.Type != "" && !strings.EqualFold(a.Type, f.Type) {
+			return true
+		}
+
+		if len(exts) == 0 {
+			return false
+		}
+
+		name := a.OriginalFileName
+		if name == "" {
+			name = a.OriginalPath
+		}
+
+		return !slices.Contains(exts, strings.ToLower(filepath.Ext(name)))
+	})
+}
@@ -0,0 +1,59 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package cli
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMedia_MediaFilter(t *testing.T) {
+	t.Run("invalid type", func(t *testing.T) {
+		f := &MediaFilter{Type: "audio"}
+		if err := f.validate(); err == nil {
+			t.Error("expected an error, got nil")
+		}
+	})
+
+	t.Run("nil filter", func(t *testing.T) {
+		var f *MediaFilter
+		if err := f.validate(); err != nil {
+			t.Errorf("expected nil, got %v", err)
+		}
+	})
+
+	assets := []Asset{
+		{ID: "1", Type: "IMAGE", OriginalFileName: "a.jpg"},
+		{ID: "2", Type: "IMAGE", OriginalFileName: "b.HEIC"},
+		{ID: "3", Type: "VIDEO", OriginalFileName: "c.mp4"},
+		{ID: "4", Type: "VIDEO", OriginalPath: "/media/d.MOV"},
+	}
+
+	tests := []struct {
+		name   string
+		filter *MediaFilter
+		want   []string
+	}{
+		{"nil", nil, []string{"1", "2", "3", "4"}},
+		{"empty", &MediaFilter{}, []string{"1", "2", "3", "4"}},
+		{"image", &MediaFilter{Type: MediaImage}, []string{"1", "2"}},
+		{"video", &MediaFilter{Type: "VIDEO"}, []string{"3", "4"}},
+		{"extensions", &MediaFilter{Extensions: []string{".heic", "mov"}}, []string{"2", "4"}},
+		{"type and extensions", &MediaFilter{Type: MediaVideo, Extensions: []string{"jpg", "mp4"}}, []string{"3"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.filter.validate(); err != nil {
+				t.Errorf("expected nil, got %v", err)
+			}
+
+			if got := assetIDs(tt.filter.filter(assets)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("unexpected assets: %v (expected %v)", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,223 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/faabiosr/imt/internal/client"
+	"github.com/faabiosr/imt/internal/errors"
+)
+
+// MergeAlbumsOptions handles the options to merge albums into a target album.
+// Albums are referenced by name or ID.
+type MergeAlbumsOptions struct {
+	Target            string
+	Sources           []string
+	MergeDescriptions bool
+	MergeUsers        bool
+}
+
+// MergeAlbumsReport holds the outcome of the albums merge.
+type MergeAlbumsReport struct {
+	Assets  int
+	Users   int
+	Deleted []string
+}
+
+// MergeAlbums moves the assets of the source albums into the target album and
+// deletes the sources. Descriptions and shared users of the sources are
+// merged into the target when enabled. The merge stops before deleting any
+// source when some assets could not be added into the target.
+func MergeAlbums(ctx context.Context, cl *client.Client, opts *MergeAlbumsOptions) (*MergeAlbumsReport, error) {
+	report := &MergeAlbumsReport{}
+
+	if len(opts.Sources) == 0 {
+		return report, errors.New("at least one source album is required")
+	}
+
+	as, err := FetchAlbums(ctx, cl)
+	if err != nil {
+		return report, err
+	}
+
+	t, err := as.find(opts.Target)
+	if err != nil {
+		return report, err
+	}
+
+	target, err := fetchAlbum(ctx, cl, t.ID)
+	if err != nil {
+		return report, err
+	}
+
+	sources := make([]*albumDetails, 0, len(opts.Sources))
+
+	for _, ref := range opts.Sources {
+		a, err := as.find(ref)
+		if err != nil {
+			return report, err
+		}
+
+		if a.ID == target.ID || slices.ContainsFunc(sources, func(s *albumDetails) bool { return s.ID == a.ID }) {
+			return report, errors.Errorf("album %q is merged more than once", ref)
+		}
+
+		source, err := fetchAlbum(ctx, cl, a.ID)
+		if err != nil {
+			return report, err
+		}
+
+		sources = append(sources, source)
+	}
+
+	if err := mergeAlbumAssets(ctx, cl, target, sources, report); err != nil {
+		return report, err
+	}
+
+	if opts.MergeDescriptions {
+		if err := mergeAlbumDescriptions(ctx, cl, target, sources); err != nil {
+			return report, err
+		}
+	}
+
+	if opts.MergeUsers {
+		if err := mergeAlbumUsers(ctx, cl, target, sources, report); err != nil {
+			return report, err
+		}
+	}
+
+	for _, source := range sources {
+		if err := deleteAlbum(ctx, cl, source.ID); err != nil {
+			return report, err
+		}
+
+		report.Deleted = append(report.Deleted, source.Name)
+	}
+
+	return report, nil
+}
+
+// mergeAlbumAssets adds the source assets missing in the target, the assets
+// already in the target are counted as merged.
+func mergeAlbumAssets(ctx context.Context, cl *client.Client, target *albumDetails, sources []*albumDetails, report *MergeAlbumsReport) error {
+	members := make(map[string]bool, len(target.Assets))
+	for _, a := range target.Assets {
+		members[a.ID] = true
+	}
+
+	ids := []string{}
+
+	for _, source := range sources {
+		for _, a := range source.Assets {
+			if !members[a.ID] {
+				members[a.ID] = true
+				ids = append(ids, a.ID)
+			}
+		}
+	}
+
+	if len(ids) == 0 {
+		return nil
+	}
+
+	results, err := addAssetsToAlbum(ctx, cl, target.ID, ids)
+	if err != nil {
+		return err
+	}
+
+	failed := results.failed()
+	report.Assets = len(results) - len(failed)
+
+	if len(failed) > 0 {
+		return errors.Errorf("unable to merge %d assets, no album deleted:\n%w", len(failed), failed.err())
+	}
+
+	return nil
+}
+
+// mergeAlbumDescriptions appends the source descriptions not found in the
+// target description, one per paragraph.
+func mergeAlbumDescriptions(ctx context.Context, cl *client.Client, target *albumDetails, sources []*albumDetails) error {
+	descriptions := []string{}
+	if d := strings.TrimSpace(target.Description); d != "" {
+		descriptions = append(descriptions, d)
+	}
+
+	for _, source := range sources {
+		if d := strings.TrimSpace(source.Description); d != "" && !slices.Contains(descriptions, d) {
+			descriptions = append(descriptions, d)
+		}
+	}
+
+	description := strings.Join(descriptions, "\n\n")
+	if description == strings.TrimSpace(target.Description) {
+		return nil
+	}
+
+	return updateAlbum(ctx, cl, target.ID, map[string]any{"description": description})
+}
+
+// mergeAlbumUsers shares the target with the users of the sources, keeping
+// their roles.
+func mergeAlbumUsers(ctx context.Context, cl *client.Client, target *albumDetails, sources []*albumDetails, report *MergeAlbumsReport) error {
+	shared := map[string]bool{target.OwnerID: true}
+	for _, u := range target.AlbumUsers {
+		shared[u.User.ID] = true
+	}
+
+	users := []map[string]string{}
+
+	for _, source := range sources {
+		for _, u := range source.AlbumUsers {
+			if !shared[u.User.ID] {
+				shared[u.User.ID] = true
+				users = append(users, map[string]string{"userId": u.User.ID, "role": u.Role})
+			}
+		}
+	}
+
+	if len(users) == 0 {
+		return nil
+	}
+
+	if err := addAlbumUsers(ctx, cl, target.ID, users); err != nil {
+		return err
+	}
+
+	report.Users = len(users)
+
+	return nil
+}
+
+// updateAlbum updates the fields of an album.
+func updateAlbum(ctx context.Context, cl *client.Client, id string, fields map[string]any) error {
+	resource, _ := url.Parse(fmt.Sprintf("/api/albums/%s", id))
+
+	req, err := cl.NewRequest(ctx, http.MethodPatch, resource, fields)
+	if err != nil {
+		return err
+	}
+
+	return cl.Do(req, nil)
+}
+
+// deleteAlbum deletes an album, keeping its assets.
+func deleteAlbum(ctx context.Context, cl *client.Client, id string) error {
+	resource, _ := url.Parse(fmt.Sprintf("/api/albums/%s", id))
+
+	req, err := cl.NewRequest(ctx, http.MethodDelete, resource, nil)
+	if err != nil {
+		return err
+	}
+
+	return cl.Do(req, nil)
+}
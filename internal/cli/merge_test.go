@@ -0,0 +1,181 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+
+	"github.com/faabiosr/imt/internal/client"
+)
+
+func TestMerge_MergeAlbums(t *testing.T) {
+	albums := `[
+		{"id": "a1", "albumName": "Beach"},
+		{"id": "a2", "albumName": "beach"},
+		{"id": "a3", "albumName": "Beach "},
+		{"id": "a4", "albumName": "Trip"},
+		{"id": "a5", "albumName": "Trip"}
+	]`
+
+	t.Run("invalid albums", func(t *testing.T) {
+		hc := http.DefaultClient
+
+		httpmock.ActivateNonDefault(hc)
+		defer httpmock.DeactivateAndReset()
+
+		httpmock.RegisterResponder(http.MethodGet, testHost+"/api/albums", httpmock.NewStringResponder(http.StatusOK, albums))
+		httpmock.RegisterResponder(http.MethodGet, `=~^`+testHost+`/api/albums/(\w+)`, httpmock.NewStringResponder(http.StatusOK, `{}`))
+
+		baseURL, _ := url.Parse(testHost)
+		cl := client.NewWithHTTPClient(baseURL, testAPIKey, hc)
+
+		options := []*MergeAlbumsOptions{
+			{Target: "Beach"},
+			{Target: "Sea", Sources: []string{"beach"}},
+			{Target: "Beach", Sources: []string{"Trip"}},
+			{Target: "Beach", Sources: []string{"Beach"}},
+			{Target: "Beach", Sources: []string{"beach", "a2"}},
+		}
+
+		for _, opts := range options {
+			if _, err := MergeAlbums(context.Background(), cl, opts); err == nil {
+				t.Errorf("expected an error for %+v, got nil", opts)
+			}
+		}
+
+		if n := httpmock.GetCallCountInfo()["DELETE "+testHost+"/api/albums/a2"]; n != 0 {
+			t.Errorf("unexpected albums deleted: %d", n)
+		}
+	})
+
+	t.Run("merges albums", func(t *testing.T) {
+		hc := http.DefaultClient
+
+		httpmock.ActivateNonDefault(hc)
+		defer httpmock.DeactivateAndReset()
+
+		httpmock.RegisterResponder(http.MethodGet, testHost+"/api/albums", httpmock.NewStringResponder(http.StatusOK, albums))
+
+		details := map[string]string{
+			"a1": `{"id": "a1", "albumName": "Beach", "ownerId": "u1", "description": "Summer",
+				"albumUsers": [{"user": {"id": "u2"}, "role": "editor"}], "assets": [{"id": "1"}]}`,
+			"a2": `{"id": "a2", "albumName": "beach", "ownerId": "u1", "description": "Summer",
+				"albumUsers": [{"user": {"id": "u2"}, "role": "viewer"}], "assets": [{"id": "1"}, {"id": "2"}]}`,
+			"a3": `{"id": "a3", "albumName": "Beach ", "ownerId": "u1", "description": "Algarve",
+				"albumUsers": [{"user": {"id": "u3"}, "role": "viewer"}], "assets": [{"id": "2"}, {"id": "3"}]}`,
+		}
+
+		for id, body := range details {
+			httpmock.RegisterResponder(http.MethodGet, testHost+"/api/albums/"+id, httpmock.NewStringResponder(http.StatusOK, body))
+		}
+
+		bodies := map[string]any{}
+
+		record := func(req *http.Request) (*http.Response, error) {
+			var body any
+			if req.Method != http.MethodDelete {
+				if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+					return nil, err
+				}
+			}
+
+			bodies[req.Method+" "+req.URL.Path] = body
+
+			// the asset 3 was added into the target meanwhile.
+			if req.Method == http.MethodPut && strings.HasSuffix(req.URL.Path, "/assets") {
+				return httpmock.NewStringResponse(http.StatusOK, `[{"id": "2", "success": true}, {"id": "3", "success": false, "error": "duplicate"}]`), nil
+			}
+
+			return httpmock.NewStringResponse(http.StatusOK, `{}`), nil
+		}
+
+		httpmock.RegisterResponder(http.MethodPut, testHost+"/api/albums/a1/assets", record)
+		httpmock.RegisterResponder(http.MethodPut, testHost+"/api/albums/a1/users", record)
+		httpmock.RegisterResponder(http.MethodPatch, testHost+"/api/albums/a1", record)
+		httpmock.RegisterResponder(http.MethodDelete, testHost+"/api/albums/a2", record)
+		httpmock.RegisterResponder(http.MethodDelete, testHost+"/api/albums/a3", record)
+
+		baseURL, _ := url.Parse(testHost)
+		cl := client.NewWithHTTPClient(baseURL, testAPIKey, hc)
+
+		opts := &MergeAlbumsOptions{
+			Target:            "Beach",
+			Sources:           []string{"beach", "a3"},
+			MergeDescriptions: true,
+			MergeUsers:        true,
+		}
+
+		report, err := MergeAlbums(context.Background(), cl, opts)
+		if err != nil {
+			t.Fatalf("expected nil, got %v", err)
+		}
+
+		expected := &MergeAlbumsReport{Assets: 2, Users: 1, Deleted: []string{"beach", "Beach "}}
+
+		if !reflect.DeepEqual(report, expected) {
+			t.Errorf("unexpected report: %+v (expected %+v)", report, expected)
+		}
+
+		expectedBodies := map[string]any{
+			"PUT /api/albums/a1/assets": map[string]any{"ids": []any{"2", "3"}},
+			"PUT /api/albums/a1/users": map[string]any{"albumUsers": []any{
+				map[string]any{"userId": "u3", "role": "viewer"},
+			}},
+			"PATCH /api/albums/a1":  map[string]any{"description": "Summer\n\nAlgarve"},
+			"DELETE /api/albums/a2": nil,
+			"DELETE /api/albums/a3": nil,
+		}
+
+		if !reflect.DeepEqual(bodies, expectedBodies) {
+			t.Errorf("unexpected requests: %v (expected %v)", bodies, expectedBodies)
+		}
+	})
+
+	t.Run("failed assets", func(t *testing.T) {
+		hc := http.DefaultClient
+
+		httpmock.ActivateNonDefault(hc)
+		defer httpmock.DeactivateAndReset()
+
+		httpmock.RegisterResponder(http.MethodGet, testHost+"/api/albums", httpmock.NewStringResponder(http.StatusOK, albums))
+		httpmock.RegisterResponder(http.MethodGet, testHost+"/api/albums/a1", httpmock.NewStringResponder(http.StatusOK, `{"id": "a1", "assets": []}`))
+		httpmock.RegisterResponder(
+			http.MethodGet,
+			testHost+"/api/albums/a2",
+			httpmock.NewStringResponder(http.StatusOK, `{"id": "a2", "assets": [{"id": "1"}, {"id": "2"}]}`),
+		)
+		httpmock.RegisterResponder(
+			http.MethodPut,
+			testHost+"/api/albums/a1/assets",
+			httpmock.NewStringResponder(http.StatusOK, `[{"id": "1", "success": true}, {"id": "2", "success": false, "error": "no_permission"}]`),
+		)
+		httpmock.RegisterResponder(http.MethodDelete, testHost+"/api/albums/a2", httpmock.NewStringResponder(http.StatusOK, ""))
+
+		baseURL, _ := url.Parse(testHost)
+		cl := client.NewWithHTTPClient(baseURL, testAPIKey, hc)
+
+		report, err := MergeAlbums(context.Background(), cl, &MergeAlbumsOptions{Target: "Beach", Sources: []string{"beach"}})
+		if err == nil || !strings.Contains(err.Error(), `asset "2": no_permission`) {
+			t.Errorf("unexpected error: %v (expected no_permission)", err)
+		}
+
+		if report.Assets != 1 || len(report.Deleted) != 0 {
+			t.Errorf("unexpected report: %+v", report)
+		}
+
+		if n := httpmock.GetCallCountInfo()["DELETE "+testHost+"/api/albums/a2"]; n != 0 {
+			t.Errorf("unexpected albums deleted: %d (expected 0)", n)
+		}
+	})
+}
@@ -0,0 +1,338 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"maps"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+	"unicode"
+
+	"github.com/faabiosr/imt/internal/errors"
+)
+
+// nameData represents the folder information available in album name templates.
+type nameData struct {
+	// Name is the folder name.
+	Name string
+
+	// Path is the folder path as seen by Immich.
+	Path string
+
+	// Folder is the path of the named folder, it differs from Path when the
+	// name comes from a parent folder.
+	Folder string
+
+	// Segments are the folder names of the path.
+	Segments []string
+
+	// Depth is the folder level after the skipped levels, starting from 1.
+	Depth int
+
+	// Parent is the name of the parent folder.
+	Parent string
+
+	// Parents are the names of all the parent folders.
+	Parents []string
+
+	// Date is the date parsed from the folder names, zero if not found.
+	Date time.Time
+}
+
+// nameFuncs are the helper functions available in album name templates.
+var nameFuncs = template.FuncMap{
+	"title":      titleCase,
+	"upper":      strings.ToUpper,
+	"lower":      strings.ToLower,
+	"trim":       strings.TrimSpace,
+	"trimPrefix": func(prefix, s string) string { return strings.TrimPrefix(s, prefix) },
+	"trimSuffix": func(suffix, s string) string { return strings.TrimSuffix(s, suffix) },
+	"replace":    func(old, repl, s string) string { return strings.ReplaceAll(s, old, repl) },
+	"join":       func(sep string, s []string) string { return strings.Join(s, sep) },
+	"regex":      regexCapture,
+}
+
+// RenameRule represents a regexp rule to rename albums, the replacement
+// supports capture groups like $1 or ${name}.
+type RenameRule struct {
+	Pattern     string `json:"pattern"`
+	Replacement string `json:"replacement"`
+}
+
+// String returns the rule representation.
+func (r RenameRule) String() string {
+	return fmt.Sprintf("%q => %q", r.Pattern, r.Replacement)
+}
+
+// AlbumNameExplanation describes how the album name of a folder was resolved.
+type AlbumNameExplanation struct {
+	Folder string
+	Album  string
+	Steps  []string
+}
+
+// renameRule is a compiled rename rule.
+type renameRule struct {
+	RenameRule
+	re *regexp.Regexp
+}
+
+// albumNamer resolves the album name of a folder.
+type albumNamer struct {
+	tpl          *template.Template
+	albums       map[string]string
+	rules        []renameRule
+	dates        *FolderDateOptions
+	albumDates   map[string]time.Time
+	owners       *albumOwners
+	key          normalizer
+	explanations []AlbumNameExplanation
+}
+
+// newAlbumNamer creates an album namer based on the options set, the name
+// template is parsed up front to report errors before walking the folders.
+func newAlbumNamer(opts *AutoCreateAlbumsOptions) (*albumNamer, error) {
+	key, err := newNormalizer(opts.Normalize)
+	if err != nil {
+		return nil, err
+	}
+
+	n := &albumNamer{
+		albums:     make(map[string]string, len(opts.Albums)),
+		dates:      opts.Dates,
+		albumDates: map[string]time.Time{},
+		owners:     newAlbumOwners(opts.OnConflict, key),
+		key:        key,
+	}
+
+	for folder, album := range opts.Albums {
+		n.albums[key(folder)] = album
+	}
+
+	if err := validateConflictPolicy(opts.OnConflict); err != nil {
+		return nil, err
+	}
+
+	if n.dates != nil {
+		if err := n.dates.validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	for i, r := range opts.RenameRules {
+		re, err := regexp.Compile(r.Pattern)
+		if err != nil {
+			return nil, errors.Errorf("invalid rename rule #%d %q: %w", i+1, r.Pattern, err)
+		}
+
+		n.rules = append(n.rules, renameRule{RenameRule: r, re: re})
+	}
+
+	if opts.NameTemplate == "" {
+		return n, nil
+	}
+
+	tpl, err := template.New("name").Funcs(nameFuncs).Parse(opts.NameTemplate)
+	if err != nil {
+		return nil, errors.Errorf("invalid album name template: %w", err)
+	}
+
+	n.tpl = tpl
+
+	return n, nil
+}
+
+// name returns the album name for the folder. Exact album mappings take
+// precedence over the name template, then the rename rules are applied in
+// order to the mapped name, the folder name or the rendered template.
+func (n *albumNamer) name(data *nameData) (string, error) {
+	exp := AlbumNameExplanation{Folder: data.Path}
+
+	name, err := n.base(data, &exp)
+	if err != nil {
+		return "", err
+	}
+
+	resolved, err := n.owners.resolve(name, data.Folder, data.Parent)
+	if err != nil {
+		return "", err
+	}
+
+	if resolved != name {
+		exp.Steps = append(exp.Steps, "conflict "+n.owners.policy)
+		name = resolved
+	}
+
+	exp.Album = name
+	n.explanations = append(n.explanations, exp)
+
+	if d, ok := n.albumDates[name]; n.dates != nil && !data.Date.IsZero() && (!ok || data.Date.Before(d)) {
+		n.albumDates[name] = data.Date
+	}
+
+	return name, nil
+}
+
+// base resolves the album name, recording every step applied in exp.
+func (n *albumNamer) base(data *nameData, exp *AlbumNameExplanation) (string, error) {
+	name, err := n.mapped(data, exp)
+	if err != nil {
+		return "", err
+	}
+
+	for i, r := range n.rules {
+		if !r.re.MatchString(name) {
+			continue
+		}
+
+		name = r.re.ReplaceAllString(name, r.Replacement)
+		exp.Steps = append(exp.Steps, fmt.Sprintf("rule #%d (%s)", i+1, r))
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", errors.Errorf("album name resolved to an empty name for %q", data.Path)
+	}
+
+	// folders named by a date, like "2022", already carry their year.
+	if n.dates != nil && n.dates.Group == DateGroupYear && !data.Date.IsZero() && !isFolderDate(name, n.dates.layouts()) {
+		name = yearSuffix(name, data.Date)
+		exp.Steps = append(exp.Steps, "year group")
+	}
+
+	return name, nil
+}
+
+// mapped returns the exact album mapping of the folder, falling back to the
+// folder name or the rendered template.
+func (n *albumNamer) mapped(data *nameData, exp *AlbumNameExplanation) (string, error) {
+	if v, ok := n.albums[n.key(data.Name)]; ok {
+		exp.Steps = append(exp.Steps, "album mapping")
+		return v, nil
+	}
+
+	if n.dates != nil {
+		if name, date, ok := stripFolderDate(data.Name, n.dates.layouts()); ok {
+			data.Name, data.Date = name, date
+			exp.Steps = append(exp.Steps, "folder date")
+		}
+	}
+
+	if n.tpl == nil {
+		return data.Name, nil
+	}
+
+	var buf bytes.Buffer
+	if err := n.tpl.Execute(&buf, data); err != nil {
+		return "", errors.Errorf("unable to render album name for %q: %w", data.Path, err)
+	}
+
+	exp.Steps = append(exp.Steps, "name template")
+
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// description returns the album description based on the album date, empty
+// if the date description is not set or the album has no date.
+func (n *albumNamer) description(name string) string {
+	d, ok := n.albumDates[name]
+	if n.dates == nil || n.dates.Description == "" || !ok {
+		return ""
+	}
+
+	return d.Format(n.dates.Description)
+}
+
+// sorted returns the album names ordered by date, albums without date are
+// placed at the end ordered by name.
+func (n *albumNamer) sorted(groups map[string][]string) []string {
+	names := slices.Sorted(maps.Keys(groups))
+
+	slices.SortStableFunc(names, func(a, b string) int {
+		da, oka := n.albumDates[a]
+		db, okb := n.albumDates[b]
+
+		switch {
+		case oka && okb:
+			return da.Compare(db)
+		case oka:
+			return -1
+		case okb:
+			return 1
+		default:
+			return 0
+		}
+	})
+
+	return names
+}
+
+// newNameData creates the template data of the segment at index i.
+func newNameData(path string, segments []string, i, skip int) *nameData {
+	parents := segments[:i]
+
+	folder := path
+	if rest := segments[i+1:]; len(rest) > 0 {
+		folder = strings.TrimSuffix(path, string(os.PathSeparator)+strings.Join(rest, string(os.PathSeparator)))
+	}
+
+	data := &nameData{
+		Name:     segments[i],
+		Path:     path,
+		Folder:   folder,
+		Segments: segments,
+		Depth:    i - skip + 1,
+		Parents:  parents,
+		Date:     parseFolderDate(segments[:i+1]),
+	}
+
+	if len(parents) > 0 {
+		data.Parent = parents[len(parents)-1]
+	}
+
+	return data
+}
+
+// titleCase upper cases the first letter of every word.
+func titleCase(s string) string {
+	prev := ' '
+
+	return strings.Map(func(r rune) rune {
+		defer func() { prev = r }()
+
+		if unicode.IsSpace(prev) || prev == '-' || prev == '_' {
+			return unicode.ToTitle(r)
+		}
+
+		return r
+	}, s)
+}
+
+// regexCapture returns the first capture group of the pattern found in s, or
+// the whole match when the pattern has no groups.
+func regexCapture(pattern, s string) (string, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return "", err
+	}
+
+	m := re.FindStringSubmatch(s)
+
+	switch len(m) {
+	case 0:
+		return "", nil
+	case 1:
+		return m[0], nil
+	default:
+		return m[1], nil
+	}
+}
@@ -0,0 +1,152 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package cli
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNaming_albumNamer(t *testing.T) {
+	t.Run("invalid template", func(t *testing.T) {
+		_, err := newAlbumNamer(&AutoCreateAlbumsOptions{NameTemplate: "{{ .Name"})
+		if err == nil {
+			t.Error("expected an error, got nil")
+		}
+	})
+
+	t.Run("invalid rename rule", func(t *testing.T) {
+		_, err := newAlbumNamer(&AutoCreateAlbumsOptions{
+			RenameRules: []RenameRule{{Pattern: "("}},
+		})
+		if err == nil || !strings.Contains(err.Error(), "rule #1") {
+			t.Errorf("unexpected error: %v (expected rule #1)", err)
+		}
+	})
+
+	tests := []struct {
+		name     string
+		template string
+		albums   map[string]string
+		rules    []RenameRule
+		path     string
+		want     string
+		steps    []string
+		err      string
+	}{
+		{
+			name: "folder name",
+			path: "/2023/07 - Lisbon/day2",
+			want: "day2",
+		},
+		{
+			name:     "exact mapping takes precedence",
+			template: "{{ upper .Name }}",
+			albums:   map[string]string{"day2": "Day Two"},
+			path:     "/2023/07 - Lisbon/day2",
+			want:     "Day Two",
+		},
+		{
+			name:   "rules applied after exact mapping",
+			albums: map[string]string{"ana": "Ana_Dias"},
+			rules:  []RenameRule{{Pattern: "_", Replacement: " "}},
+			path:   "/people/ana",
+			want:   "Ana Dias",
+			steps:  []string{"album mapping", `rule #1 ("_" => " ")`},
+		},
+		{
+			name: "rules applied in order",
+			rules: []RenameRule{
+				{Pattern: `^\d{4}-\d{2}-\d{2}[ _]`, Replacement: ""},
+				{Pattern: "_", Replacement: " "},
+				{Pattern: "^nope$", Replacement: "yes"},
+			},
+			path:  "/2024/2024-05-12_Company_Summit",
+			want:  "Company Summit",
+			steps: []string{`rule #1 ("^\\d{4}-\\d{2}-\\d{2}[ _]" => "")`, `rule #2 ("_" => " ")`},
+		},
+		{
+			name:     "rules with capture groups after template",
+			template: "{{ .Parent }} {{ .Name }}",
+			rules:    []RenameRule{{Pattern: `^(\w+) (\w+)$`, Replacement: "$2 ($1)"}},
+			path:     "/people/Ana",
+			want:     "Ana (people)",
+			steps:    []string{"name template", `rule #1 ("^(\\w+) (\\w+)$" => "$2 ($1)")`},
+		},
+		{
+			name:     "parent and date",
+			template: `{{ regex "^\\d{2} - (.*)$" .Parent }} ({{ .Date.Format "2006-01" }})`,
+			path:     "/2023/07 - Lisbon/day2",
+			want:     "Lisbon (2023-07)",
+		},
+		{
+			name:     "helpers",
+			template: `{{ .Name | replace "_" " " | title | trim }} {{ .Depth }}`,
+			path:     "/people/ana_dias ",
+			want:     "Ana Dias 2",
+		},
+		{
+			name:     "segments",
+			template: `{{ join "-" .Parents }}`,
+			path:     "/a/b/c",
+			want:     "a-b",
+		},
+		{
+			name:     "empty name",
+			template: `{{ trimPrefix "x" .Name }}`,
+			path:     "/x",
+			err:      "empty name",
+		},
+		{
+			name:     "execution failure",
+			template: `{{ regex "(" .Name }}`,
+			path:     "/x",
+			err:      "unable to render",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, err := newAlbumNamer(&AutoCreateAlbumsOptions{
+				NameTemplate: tt.template,
+				Albums:       tt.albums,
+				RenameRules:  tt.rules,
+			})
+			if err != nil {
+				t.Fatalf("expected nil, got %v", err)
+			}
+
+			segments := strings.Split(tt.path, string(os.PathSeparator))[1:]
+
+			got, err := n.name(newNameData(tt.path, segments, len(segments)-1, 0))
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Errorf("unexpected error: %v (expected %s)", err, tt.err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Errorf("expected nil, got %v", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("unexpected name: %s (expected %s)", got, tt.want)
+			}
+
+			if tt.steps == nil {
+				return
+			}
+
+			if steps := n.explanations[0].Steps; !reflect.DeepEqual(steps, tt.steps) {
+				t.Errorf("unexpected steps: %v (expected %v)", steps, tt.steps)
+			}
+		})
+	}
+}
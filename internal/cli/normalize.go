@@ -0,0 +1,65 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package cli
+
+import (
+	"strings"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+
+	"github.com/faabiosr/imt/internal/errors"
+)
+
+// Normalizations applied to album names when matching them.
+const (
+	NormalizeNFC        = "nfc"
+	NormalizeCaseFold   = "casefold"
+	NormalizeWhitespace = "whitespace"
+)
+
+// normalizer converts an album name into the key used to match albums.
+type normalizer func(string) string
+
+// newNormalizer creates a normalizer applying the normalizations in a fixed
+// order: unicode composition, whitespace collapse and case folding.
+func newNormalizer(names []string) (normalizer, error) {
+	var nfc, fold, space bool
+
+	for _, n := range names {
+		switch strings.ToLower(strings.TrimSpace(n)) {
+		case NormalizeNFC:
+			nfc = true
+		case NormalizeCaseFold:
+			fold = true
+		case NormalizeWhitespace:
+			space = true
+		default:
+			return nil, errors.Errorf(
+				"invalid normalization %q, must be %s, %s or %s",
+				n, NormalizeNFC, NormalizeCaseFold, NormalizeWhitespace,
+			)
+		}
+	}
+
+	folder := cases.Fold()
+
+	return func(s string) string {
+		if nfc {
+			s = norm.NFC.String(s)
+		}
+
+		if space {
+			s = strings.Join(strings.Fields(s), " ")
+		}
+
+		if fold {
+			s = folder.String(s)
+		}
+
+		return s
+	}, nil
+}
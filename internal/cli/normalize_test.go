@@ -0,0 +1,53 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package cli
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalize_newNormalizer(t *testing.T) {
+	const (
+		nfc = "Caf\u00e9"
+		nfd = "Cafe\u0301"
+	)
+
+	t.Run("invalid normalization", func(t *testing.T) {
+		_, err := newNormalizer([]string{"nfkd"})
+		if err == nil {
+			t.Error("expected an error, got nil")
+		}
+	})
+
+	tests := []struct {
+		names []string
+		a, b  string
+		equal bool
+	}{
+		{nil, nfc, nfd, false},
+		{nil, nfc, nfc, true},
+		{[]string{NormalizeNFC}, nfc, nfd, true},
+		{[]string{NormalizeNFC}, "beach", "Beach", false},
+		{[]string{NormalizeCaseFold}, "beach", "Beach", true},
+		{[]string{NormalizeCaseFold}, "STRASSE", "straße", true},
+		{[]string{NormalizeWhitespace}, " Summer  Trip", "Summer Trip ", true},
+		{[]string{" NFC", "CaseFold ", "whitespace"}, "cafe\u0301  TRIP", "Café trip", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.names, ","), func(t *testing.T) {
+			key, err := newNormalizer(tt.names)
+			if err != nil {
+				t.Fatalf("expected nil, got %v", err)
+			}
+
+			if equal := key(tt.a) == key(tt.b); equal != tt.equal {
+				t.Errorf("unexpected comparison of %q and %q: %t (expected %t)", tt.a, tt.b, equal, tt.equal)
+			}
+		})
+	}
+}
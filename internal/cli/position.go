@@ -0,0 +1,268 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2/unstable"
+	"gopkg.in/yaml.v3"
+)
+
+// position is a line and column in a config file, starting from 1.
+type position struct {
+	line   int
+	column int
+}
+
+// configPositions holds the position of the config values by path, like
+// "jobs[1].exclude[0]".
+type configPositions map[string]position
+
+// lookup returns the position of the path, or of its closest parent when the
+// path has no position.
+func (p configPositions) lookup(path string) position {
+	for {
+		if pos, ok := p[path]; ok || path == "" {
+			return pos
+		}
+
+		i := strings.LastIndexAny(path, ".[")
+		if i < 0 {
+			i = 0
+		}
+
+		path = path[:i]
+	}
+}
+
+// offsetPosition converts a byte offset into a position.
+func offsetPosition(content []byte, offset int) position {
+	offset = min(max(offset, 0), len(content))
+	lead := content[:offset]
+
+	return position{
+		line:   bytes.Count(lead, []byte{'\n'}) + 1,
+		column: len(lead) - bytes.LastIndexByte(lead, '\n'),
+	}
+}
+
+// indexPositions returns the position of the config values, positions are
+// best effort and an invalid content results in partial positions.
+func indexPositions(content []byte, ext string) configPositions {
+	switch ext {
+	case ".json":
+		return jsonPositions(content)
+	case ".yaml", ".yml":
+		return yamlPositions(content)
+	case ".toml":
+		return tomlPositions(content)
+	default:
+		return configPositions{}
+	}
+}
+
+// jsonPositions indexes the positions of a JSON document.
+func jsonPositions(content []byte) configPositions {
+	pos := configPositions{}
+	dec := json.NewDecoder(bytes.NewReader(content))
+
+	// start returns the position of the next token after the offset.
+	start := func(offset int64) position {
+		i := int(offset)
+		for i < len(content) && strings.IndexByte(" \t\r\n,:", content[i]) >= 0 {
+			i++
+		}
+
+		return offsetPosition(content, i)
+	}
+
+	var walk func(path string) error
+
+	walk = func(path string) error {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+
+		switch tok {
+		case json.Delim('{'):
+			for dec.More() {
+				offset := dec.InputOffset()
+
+				key, err := dec.Token()
+				if err != nil {
+					return err
+				}
+
+				child := joinPath(path, fmt.Sprint(key))
+				pos[child] = start(offset)
+
+				if err := walk(child); err != nil {
+					return err
+				}
+			}
+		case json.Delim('['):
+			for i := 0; dec.More(); i++ {
+				child := fmt.Sprintf("%s[%d]", path, i)
+				pos[child] = start(dec.InputOffset())
+
+				if err := walk(child); err != nil {
+					return err
+				}
+			}
+		default:
+			return nil
+		}
+
+		// closing delimiter.
+		_, err = dec.Token()
+
+		return err
+	}
+
+	_ = walk("")
+
+	return pos
+}
+
+// yamlPositions indexes the positions of a YAML document.
+func yamlPositions(content []byte) configPositions {
+	pos := configPositions{}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return pos
+	}
+
+	var walk func(n *yaml.Node, path string)
+
+	walk = func(n *yaml.Node, path string) {
+		switch n.Kind {
+		case yaml.DocumentNode:
+			for _, c := range n.Content {
+				walk(c, path)
+			}
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(n.Content); i += 2 {
+				key, value := n.Content[i], n.Content[i+1]
+
+				child := joinPath(path, key.Value)
+				pos[child] = position{line: key.Line, column: key.Column}
+
+				walk(value, child)
+			}
+		case yaml.SequenceNode:
+			for i, c := range n.Content {
+				child := fmt.Sprintf("%s[%d]", path, i)
+				pos[child] = position{line: c.Line, column: c.Column}
+
+				walk(c, child)
+			}
+		}
+	}
+
+	walk(&doc, "")
+
+	return pos
+}
+
+// tomlPositions indexes the positions of a TOML document. Positions of values
+// inside arrays are only indexed for inline tables.
+func tomlPositions(content []byte) configPositions {
+	pos := configPositions{}
+	arrays := map[string]int{}
+
+	p := unstable.Parser{}
+	p.Reset(content)
+
+	shape := func(n *unstable.Node) position {
+		s := p.Shape(n.Raw)
+		return position{line: s.Start.Line, column: s.Start.Column}
+	}
+
+	// table resolves the path of a table header, the keys of array tables
+	// refer to their last element.
+	table := func(n *unstable.Node, array bool) string {
+		path := ""
+
+		for it := n.Key(); it.Next(); {
+			k := it.Node()
+			path = joinPath(path, string(k.Data))
+
+			switch {
+			case array && it.IsLast():
+				i := arrays[path]
+				arrays[path] = i + 1
+				path = fmt.Sprintf("%s[%d]", path, i)
+			case arrays[path] > 0:
+				path = fmt.Sprintf("%s[%d]", path, arrays[path]-1)
+			}
+
+			if _, ok := pos[path]; !ok {
+				pos[path] = shape(k)
+			}
+		}
+
+		return path
+	}
+
+	var (
+		keyValue func(n *unstable.Node, prefix string)
+		value    func(n *unstable.Node, path string)
+	)
+
+	value = func(n *unstable.Node, path string) {
+		switch n.Kind {
+		case unstable.InlineTable:
+			for it := n.Children(); it.Next(); {
+				keyValue(it.Node(), path)
+			}
+		case unstable.Array:
+			i := 0
+			for it := n.Children(); it.Next(); i++ {
+				if c := it.Node(); c.Kind == unstable.InlineTable {
+					value(c, fmt.Sprintf("%s[%d]", path, i))
+				}
+			}
+		}
+	}
+
+	keyValue = func(n *unstable.Node, prefix string) {
+		path := prefix
+
+		for it := n.Key(); it.Next(); {
+			k := it.Node()
+			path = joinPath(path, string(k.Data))
+
+			if _, ok := pos[path]; !ok {
+				pos[path] = shape(k)
+			}
+		}
+
+		value(n.Value(), path)
+	}
+
+	prefix := ""
+
+	for p.NextExpression() {
+		e := p.Expression()
+
+		switch e.Kind {
+		case unstable.Table:
+			prefix = table(e, false)
+		case unstable.ArrayTable:
+			prefix = table(e, true)
+		case unstable.KeyValue:
+			keyValue(e, prefix)
+		}
+	}
+
+	return pos
+}
@@ -0,0 +1,244 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package cli
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+	"sync"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/faabiosr/imt/internal/client"
+	"github.com/faabiosr/imt/internal/errors"
+)
+
+// Kinds of albums found by the prune, in the order they are detected.
+const (
+	PruneEmpty           = "empty"
+	PruneTrashed         = "trashed"
+	PruneDuplicateAssets = "duplicate-assets"
+	PruneDuplicateName   = "duplicate-name"
+)
+
+// pruneKinds are all the kinds of albums found by the prune.
+var pruneKinds = []string{PruneEmpty, PruneTrashed, PruneDuplicateAssets, PruneDuplicateName}
+
+// pruneConcurrency is the number of albums fetched at the same time.
+const pruneConcurrency = 8
+
+// PruneOptions handles the options to find albums to clean up. Without kinds,
+// all of them are found. The normalizations are applied to the album names
+// when finding duplicates.
+type PruneOptions struct {
+	Kinds     []string
+	Normalize []string
+}
+
+// PruneCandidate is an album found by the prune. Duplicates refer to the
+// album kept.
+type PruneCandidate struct {
+	Album     Album
+	Kind      string
+	Duplicate string
+}
+
+// FindPruneCandidates returns the empty albums, the albums whose assets are
+// all trashed, and the albums duplicating the assets or the name of another
+// album. An album is reported once, by the first kind matched. From the
+// duplicates, an album already kept or the album with most assets is kept.
+func FindPruneCandidates(ctx context.Context, cl *client.Client, opts *PruneOptions) ([]PruneCandidate, error) {
+	candidates := []PruneCandidate{}
+
+	kinds, err := selectPruneKinds(opts.Kinds)
+	if err != nil {
+		return candidates, err
+	}
+
+	key, err := newNormalizer(opts.Normalize)
+	if err != nil {
+		return candidates, err
+	}
+
+	as, err := FetchAlbums(ctx, cl)
+	if err != nil {
+		return candidates, err
+	}
+
+	found := make(map[string]bool)
+
+	add := func(a Album, kind, duplicate string) {
+		found[a.ID] = true
+		candidates = append(candidates, PruneCandidate{Album: a, Kind: kind, Duplicate: duplicate})
+	}
+
+	if slices.Contains(kinds, PruneEmpty) {
+		for _, a := range as {
+			if a.AssetCount == 0 {
+				add(a, PruneEmpty, "")
+			}
+		}
+	}
+
+	var assets map[string][]Asset
+
+	if slices.Contains(kinds, PruneTrashed) || slices.Contains(kinds, PruneDuplicateAssets) {
+		if assets, err = fetchAlbumsAssets(ctx, cl, as, found); err != nil {
+			return candidates, err
+		}
+	}
+
+	if slices.Contains(kinds, PruneTrashed) {
+		for _, a := range as {
+			items := assets[a.ID]
+			if !found[a.ID] && len(items) > 0 && !slices.ContainsFunc(items, func(a Asset) bool { return !a.IsTrashed }) {
+				add(a, PruneTrashed, "")
+			}
+		}
+	}
+
+	// kept are the albums kept from duplicates, which are kept by the next
+	// duplicates found as well.
+	kept := make(map[string]bool)
+
+	// duplicates groups the albums by key, albums without key are ignored.
+	duplicates := func(kind string, key func(a Album) (string, bool)) {
+		groups := make(map[string][]Album)
+		keys := []string{}
+
+		for _, a := range as {
+			if found[a.ID] {
+				continue
+			}
+
+			k, ok := key(a)
+			if !ok {
+				continue
+			}
+
+			if _, ok := groups[k]; !ok {
+				keys = append(keys, k)
+			}
+
+			groups[k] = append(groups[k], a)
+		}
+
+		for _, k := range keys {
+			group := groups[k]
+			if len(group) < 2 {
+				continue
+			}
+
+			keep := slices.MaxFunc(group, func(a, b Album) int {
+				if kept[a.ID] != kept[b.ID] {
+					if kept[a.ID] {
+						return 1
+					}
+
+					return -1
+				}
+
+				return cmp.Compare(a.AssetCount, b.AssetCount)
+			})
+
+			kept[keep.ID] = true
+
+			for _, a := range group {
+				if !kept[a.ID] {
+					add(a, kind, keep.Name)
+				}
+			}
+		}
+	}
+
+	if slices.Contains(kinds, PruneDuplicateAssets) {
+		duplicates(PruneDuplicateAssets, func(a Album) (string, bool) {
+			ids := assetIDs(assets[a.ID])
+			slices.Sort(ids)
+
+			return strings.Join(ids, ","), len(ids) > 0
+		})
+	}
+
+	if slices.Contains(kinds, PruneDuplicateName) {
+		duplicates(PruneDuplicateName, func(a Album) (string, bool) {
+			return key(a.Name), true
+		})
+	}
+
+	return candidates, nil
+}
+
+// selectPruneKinds checks the prune kinds, all of them are selected by
+// default.
+func selectPruneKinds(kinds []string) ([]string, error) {
+	if len(kinds) == 0 {
+		return pruneKinds, nil
+	}
+
+	for _, k := range kinds {
+		if !slices.Contains(pruneKinds, k) {
+			return nil, errors.Errorf("invalid prune kind %q, must be %s", k, strings.Join(pruneKinds, ", "))
+		}
+	}
+
+	return kinds, nil
+}
+
+// fetchAlbumsAssets returns the assets of every album not skipped,
+// concurrently.
+func fetchAlbumsAssets(ctx context.Context, cl *client.Client, as Albums, skip map[string]bool) (map[string][]Asset, error) {
+	assets := make(map[string][]Asset, len(as))
+	m := sync.Mutex{}
+
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(pruneConcurrency)
+
+	for _, a := range as {
+		if skip[a.ID] {
+			continue
+		}
+
+		g.Go(func() error {
+			res, err := fetchAlbumAssets(ctx, cl, a.ID)
+			if err != nil {
+				return err
+			}
+
+			m.Lock()
+			assets[a.ID] = res
+			m.Unlock()
+
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return map[string][]Asset{}, errors.Errorf("one of the albums failed to retrieve assets: %w", err)
+	}
+
+	return assets, nil
+}
+
+// DeleteAlbums deletes the albums, keeping their assets. A failing album does
+// not stop the next ones, the error returned joins the errors of all albums.
+func DeleteAlbums(ctx context.Context, cl *client.Client, as Albums) error {
+	var errs []error
+
+	for _, a := range as {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if err := deleteAlbum(ctx, cl, a.ID); err != nil {
+			errs = append(errs, errors.Errorf("album %q: %w", a.Name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
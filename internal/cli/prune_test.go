@@ -0,0 +1,158 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+
+	"github.com/faabiosr/imt/internal/client"
+)
+
+func TestPrune_FindPruneCandidates(t *testing.T) {
+	t.Run("invalid options", func(t *testing.T) {
+		options := []*PruneOptions{
+			{Kinds: []string{"large"}},
+			{Normalize: []string{"upper"}},
+		}
+
+		for _, opts := range options {
+			if _, err := FindPruneCandidates(context.Background(), nil, opts); err == nil {
+				t.Errorf("expected an error for %+v, got nil", opts)
+			}
+		}
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		hc := http.DefaultClient
+
+		httpmock.ActivateNonDefault(hc)
+		defer httpmock.DeactivateAndReset()
+
+		httpmock.RegisterResponder(
+			http.MethodGet,
+			testHost+"/api/albums",
+			httpmock.NewStringResponder(http.StatusOK, `[{"id": "a1", "albumName": "Beach", "assetCount": 1}]`),
+		)
+
+		httpmock.RegisterResponder(
+			http.MethodGet,
+			testHost+"/api/albums/a1",
+			httpmock.NewJsonResponderOrPanic(http.StatusNotFound, json.RawMessage(`{"message": "not found"}`)),
+		)
+
+		baseURL, _ := url.Parse(testHost)
+		cl := client.NewWithHTTPClient(baseURL, testAPIKey, hc)
+
+		if _, err := FindPruneCandidates(context.Background(), cl, &PruneOptions{}); err == nil {
+			t.Error("expected an error, got nil")
+		}
+	})
+
+	albums := []Album{
+		{ID: "a1", Name: "Empty"},
+		{ID: "a2", Name: "Trash", AssetCount: 1},
+		{ID: "a3", Name: "Beach", AssetCount: 2},
+		{ID: "a4", Name: "Beach copy", AssetCount: 2},
+		{ID: "a5", Name: "beach", AssetCount: 3},
+		{ID: "a6", Name: "Lisbon", AssetCount: 1},
+		{ID: "a7", Name: "Lisbon", AssetCount: 2},
+	}
+
+	details := map[string]string{
+		"a2": `{"assets": [{"id": "1", "isTrashed": true}]}`,
+		"a3": `{"assets": [{"id": "2"}, {"id": "3"}]}`,
+		"a4": `{"assets": [{"id": "3"}, {"id": "2"}]}`,
+		"a5": `{"assets": [{"id": "2"}, {"id": "3"}, {"id": "4"}]}`,
+		"a6": `{"assets": [{"id": "5"}]}`,
+		"a7": `{"assets": [{"id": "5"}, {"id": "6"}]}`,
+	}
+
+	tests := []struct {
+		name     string
+		opts     *PruneOptions
+		expected []PruneCandidate
+	}{
+		{
+			name: "all kinds",
+			opts: &PruneOptions{Normalize: []string{NormalizeCaseFold}},
+			expected: []PruneCandidate{
+				{Album: albums[0], Kind: PruneEmpty},
+				{Album: albums[1], Kind: PruneTrashed},
+				{Album: albums[3], Kind: PruneDuplicateAssets, Duplicate: "Beach"},
+				{Album: albums[4], Kind: PruneDuplicateName, Duplicate: "Beach"},
+				{Album: albums[5], Kind: PruneDuplicateName, Duplicate: "Lisbon"},
+			},
+		},
+		{
+			name: "duplicate names",
+			opts: &PruneOptions{Kinds: []string{PruneDuplicateName}},
+			expected: []PruneCandidate{
+				{Album: albums[5], Kind: PruneDuplicateName, Duplicate: "Lisbon"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hc := http.DefaultClient
+
+			httpmock.ActivateNonDefault(hc)
+			defer httpmock.DeactivateAndReset()
+
+			httpmock.RegisterResponder(http.MethodGet, testHost+"/api/albums", httpmock.NewJsonResponderOrPanic(http.StatusOK, albums))
+
+			for id, body := range details {
+				httpmock.RegisterResponder(http.MethodGet, testHost+"/api/albums/"+id, httpmock.NewStringResponder(http.StatusOK, body))
+			}
+
+			baseURL, _ := url.Parse(testHost)
+			cl := client.NewWithHTTPClient(baseURL, testAPIKey, hc)
+
+			candidates, err := FindPruneCandidates(context.Background(), cl, tt.opts)
+			if err != nil {
+				t.Fatalf("expected nil, got %v", err)
+			}
+
+			if !reflect.DeepEqual(candidates, tt.expected) {
+				t.Errorf("unexpected candidates: %+v (expected %+v)", candidates, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPrune_DeleteAlbums(t *testing.T) {
+	hc := http.DefaultClient
+
+	httpmock.ActivateNonDefault(hc)
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder(http.MethodDelete, testHost+"/api/albums/a1", httpmock.NewStringResponder(http.StatusNoContent, ""))
+	httpmock.RegisterResponder(
+		http.MethodDelete,
+		testHost+"/api/albums/a2",
+		httpmock.NewJsonResponderOrPanic(http.StatusForbidden, json.RawMessage(`{"message": "not an owner"}`)),
+	)
+	httpmock.RegisterResponder(http.MethodDelete, testHost+"/api/albums/a3", httpmock.NewStringResponder(http.StatusNoContent, ""))
+
+	baseURL, _ := url.Parse(testHost)
+	cl := client.NewWithHTTPClient(baseURL, testAPIKey, hc)
+
+	err := DeleteAlbums(context.Background(), cl, Albums{{ID: "a1"}, {ID: "a2"}, {ID: "a3"}})
+	if err == nil {
+		t.Fatal("expected an error, got nil")
+	}
+
+	if n := httpmock.GetTotalCallCount(); n != 3 {
+		t.Errorf("unexpected albums deleted: %d (expected 3)", n)
+	}
+}
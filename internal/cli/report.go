@@ -0,0 +1,88 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/faabiosr/imt/internal/client"
+	"github.com/faabiosr/imt/internal/errors"
+)
+
+// LibraryReport summarizes the library of the user, its statistics and
+// albums.
+type LibraryReport struct {
+	GeneratedAt time.Time `json:"generated_at"`
+	Photos      int64     `json:"photos"`
+	Videos      int64     `json:"videos"`
+	Usage       int64     `json:"usage"`
+	Albums      int       `json:"albums"`
+	EmptyAlbums int       `json:"empty_albums"`
+	SharedLinks int       `json:"shared_links"`
+}
+
+// NewLibraryReport builds the report of the library.
+func NewLibraryReport(ctx context.Context, cl *client.Client) (*LibraryReport, error) {
+	si := &ServerInfo{}
+	if err := stats(ctx, cl, si); err != nil {
+		return nil, err
+	}
+
+	as, err := FetchAlbums(ctx, cl)
+	if err != nil {
+		return nil, err
+	}
+
+	links, err := FetchSharedLinks(ctx, cl)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &LibraryReport{
+		GeneratedAt: time.Now().UTC(),
+		Albums:      len(as),
+		SharedLinks: len(links),
+	}
+
+	if si.Stats != nil {
+		r.Photos, r.Videos, r.Usage = si.Stats.Photos, si.Stats.Videos, si.Stats.Usage
+	}
+
+	for _, a := range as {
+		if a.AssetCount == 0 {
+			r.EmptyAlbums++
+		}
+	}
+
+	return r, nil
+}
+
+// WriteLibraryReport writes the report as JSON into the file, replacing the
+// previous report at once.
+func WriteLibraryReport(name string, r *LibraryReport) error {
+	name = filepath.Clean(name)
+
+	content, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return errors.Errorf("unable to encode report: %w", err)
+	}
+
+	tmp := name + ".tmp"
+
+	if err := os.WriteFile(tmp, append(content, '\n'), 0o600); err != nil {
+		return errors.Errorf("unable to write report: %w", err)
+	}
+
+	if err := os.Rename(tmp, name); err != nil {
+		return errors.Errorf("unable to write report: %w", err)
+	}
+
+	return nil
+}
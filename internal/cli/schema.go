@@ -0,0 +1,277 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// AutoCreateConfigSchemaID is the identifier of the auto create config schema.
+const AutoCreateConfigSchemaID = "https://raw.githubusercontent.com/faabiosr/imt/main/auto_create.schema.json"
+
+// jsonSchema represents the subset of JSON Schema used by the config files.
+type jsonSchema struct {
+	Schema               string                 `json:"$schema,omitempty"`
+	ID                   string                 `json:"$id,omitempty"`
+	Title                string                 `json:"title,omitempty"`
+	Ref                  string                 `json:"$ref,omitempty"`
+	Type                 string                 `json:"type,omitempty"`
+	Enum                 []string               `json:"enum,omitempty"`
+	Properties           map[string]*jsonSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	AdditionalProperties any                    `json:"additionalProperties,omitempty"`
+	Items                *jsonSchema            `json:"items,omitempty"`
+	MinItems             int                    `json:"minItems,omitempty"`
+	OneOf                []*jsonSchema          `json:"oneOf,omitempty"`
+	Defs                 map[string]*jsonSchema `json:"$defs,omitempty"`
+}
+
+// AutoCreateConfigSchema returns the JSON Schema of the auto create config
+// files, generated from the auto create options.
+func AutoCreateConfigSchema() ([]byte, error) {
+	options := schemaOf(reflect.TypeFor[AutoCreateAlbumsOptions]())
+
+	job := schemaOf(reflect.TypeFor[AutoCreateAlbumsOptions]())
+	job.Properties["name"] = &jsonSchema{Type: "string"}
+	job.Required = []string{"name"}
+
+	s := &jsonSchema{
+		Schema: "https://json-schema.org/draft/2020-12/schema",
+		ID:     AutoCreateConfigSchemaID,
+		Title:  "imt album auto-create config",
+		OneOf: []*jsonSchema{
+			{Ref: "#/$defs/options"},
+			{
+				Type: "object",
+				Properties: map[string]*jsonSchema{
+					"defaults": {Ref: "#/$defs/options"},
+					"jobs":     {Type: "array", Items: &jsonSchema{Ref: "#/$defs/job"}, MinItems: 1},
+				},
+				Required:             []string{"jobs"},
+				AdditionalProperties: false,
+			},
+		},
+		Defs: map[string]*jsonSchema{
+			"options": options,
+			"job":     job,
+		},
+	}
+
+	return json.MarshalIndent(s, "", "  ")
+}
+
+// schemaOf returns the schema of the type, objects are closed to unknown
+// fields. The enum tag lists the values allowed for a field.
+func schemaOf(t reflect.Type) *jsonSchema {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return schemaOf(t.Elem())
+	case reflect.Struct:
+		s := &jsonSchema{
+			Type:                 "object",
+			Properties:           map[string]*jsonSchema{},
+			AdditionalProperties: false,
+		}
+
+		for name, f := range jsonFields(t) {
+			p := schemaOf(f.Type)
+
+			if enum := f.Tag.Get("enum"); enum != "" {
+				target := p
+				if p.Items != nil {
+					target = p.Items
+				}
+
+				target.Enum = strings.Split(enum, ",")
+			}
+
+			s.Properties[name] = p
+		}
+
+		return s
+	case reflect.Slice:
+		return &jsonSchema{Type: "array", Items: schemaOf(t.Elem())}
+	case reflect.Map:
+		return &jsonSchema{Type: "object", AdditionalProperties: schemaOf(t.Elem())}
+	case reflect.Bool:
+		return &jsonSchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &jsonSchema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &jsonSchema{Type: "number"}
+	default:
+		return &jsonSchema{Type: "string"}
+	}
+}
+
+// jsonFields returns the fields of the struct by JSON name.
+func jsonFields(t reflect.Type) map[string]reflect.StructField {
+	fields := map[string]reflect.StructField{}
+
+	for _, f := range reflect.VisibleFields(t) {
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if !f.IsExported() || name == "-" {
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+
+		fields[name] = f
+	}
+
+	return fields
+}
+
+// checkValue checks the decoded config value against the type, reporting
+// unknown fields and mismatched types at the value path.
+func checkValue(v any, t reflect.Type, path string) []ConfigIssue {
+	if v == nil {
+		return nil
+	}
+
+	invalid := func(kind string) []ConfigIssue {
+		return []ConfigIssue{{Path: path, Message: fmt.Sprintf("must be %s", kind)}}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return checkValue(v, t.Elem(), path)
+	case reflect.Struct:
+		m, ok := v.(map[string]any)
+		if !ok {
+			return invalid("an object")
+		}
+
+		fields := jsonFields(t)
+
+		var issues []ConfigIssue
+
+		for _, k := range slices.Sorted(maps.Keys(m)) {
+			f, ok := fields[k]
+			if !ok {
+				issues = append(issues, ConfigIssue{Path: joinPath(path, k), Message: unknownField(k, fields)})
+				continue
+			}
+
+			issues = append(issues, checkValue(m[k], f.Type, joinPath(path, k))...)
+		}
+
+		return issues
+	case reflect.Slice:
+		s, ok := v.([]any)
+		if !ok {
+			return invalid("a list")
+		}
+
+		var issues []ConfigIssue
+		for i, item := range s {
+			issues = append(issues, checkValue(item, t.Elem(), fmt.Sprintf("%s[%d]", path, i))...)
+		}
+
+		return issues
+	case reflect.Map:
+		m, ok := v.(map[string]any)
+		if !ok {
+			return invalid("an object")
+		}
+
+		var issues []ConfigIssue
+		for _, k := range slices.Sorted(maps.Keys(m)) {
+			issues = append(issues, checkValue(m[k], t.Elem(), joinPath(path, k))...)
+		}
+
+		return issues
+	case reflect.Bool:
+		if _, ok := v.(bool); !ok {
+			return invalid("a boolean")
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if !isInteger(v) {
+			return invalid("an integer")
+		}
+	case reflect.String:
+		if _, ok := v.(string); !ok {
+			return invalid("a string")
+		}
+	}
+
+	return nil
+}
+
+// unknownField returns the unknown field message, suggesting the closest
+// field name for typos.
+func unknownField(name string, fields map[string]reflect.StructField) string {
+	msg := fmt.Sprintf("unknown field %q", name)
+
+	best, dist := "", 3
+	for _, f := range slices.Sorted(maps.Keys(fields)) {
+		if d := editDistance(strings.ToLower(name), f); d < dist {
+			best, dist = f, d
+		}
+	}
+
+	if best != "" {
+		msg += fmt.Sprintf(", did you mean %q?", best)
+	}
+
+	return msg
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+
+		prev = cur
+	}
+
+	return prev[len(b)]
+}
+
+// isInteger reports whether the decoded value is an integer, JSON numbers
+// are decoded as float.
+func isInteger(v any) bool {
+	switch n := v.(type) {
+	case int, int64, uint64:
+		return true
+	case float64:
+		return n == math.Trunc(n)
+	default:
+		return false
+	}
+}
+
+// joinPath appends the key to the config path.
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+
+	return path + "." + key
+}
@@ -0,0 +1,127 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestSchema_AutoCreateConfigSchema(t *testing.T) {
+	schema, err := AutoCreateConfigSchema()
+	if err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+
+	t.Run("published schema", func(t *testing.T) {
+		published, err := os.ReadFile("../../auto_create.schema.json")
+		if err != nil {
+			t.Fatalf("expected nil, got %v", err)
+		}
+
+		if !bytes.Equal(bytes.TrimSpace(published), schema) {
+			t.Error("auto_create.schema.json is outdated, run: imt config schema > auto_create.schema.json")
+		}
+	})
+
+	t.Run("options", func(t *testing.T) {
+		var s struct {
+			Defs map[string]*jsonSchema `json:"$defs"`
+		}
+
+		if err := json.Unmarshal(schema, &s); err != nil {
+			t.Fatalf("expected nil, got %v", err)
+		}
+
+		options := s.Defs["options"]
+
+		if n := len(options.Properties); n != len(jsonFields(reflect.TypeFor[AutoCreateAlbumsOptions]())) {
+			t.Errorf("unexpected number of properties: %d", n)
+		}
+
+		if p := options.Properties["skip_levels"]; p.Type != "integer" {
+			t.Errorf("unexpected skip_levels type: %s (expected integer)", p.Type)
+		}
+
+		if p := options.Properties["normalize"]; !reflect.DeepEqual(p.Items.Enum, []string{"nfc", "casefold", "whitespace"}) {
+			t.Errorf("unexpected normalize enum: %v", p.Items.Enum)
+		}
+
+		if p := options.Properties["dates"].Properties["group"]; !reflect.DeepEqual(p.Enum, []string{"merge", "year"}) {
+			t.Errorf("unexpected dates group enum: %v", p.Enum)
+		}
+
+		if _, ok := s.Defs["job"].Properties["name"]; !ok {
+			t.Error("expected job name property")
+		}
+	})
+}
+
+func TestSchema_checkValue(t *testing.T) {
+	options := reflect.TypeFor[AutoCreateAlbumsOptions]()
+
+	tests := []struct {
+		name  string
+		value any
+		want  []ConfigIssue
+	}{
+		{
+			name: "valid",
+			value: map[string]any{
+				"folder":       "/photos/",
+				"skip_levels":  float64(2),
+				"min_assets":   int64(3),
+				"albums":       map[string]any{"a": "b"},
+				"dates":        nil,
+				"rename_rules": []any{map[string]any{"pattern": "_", "replacement": " "}},
+			},
+		},
+		{
+			name:  "not an object",
+			value: []any{},
+			want:  []ConfigIssue{{Message: "must be an object"}},
+		},
+		{
+			name: "mismatched types",
+			value: map[string]any{
+				"recursive":    "yes",
+				"skip_levels":  1.5,
+				"exclude":      "Raw",
+				"albums":       map[string]any{"a": 1},
+				"media":        map[string]any{"extensions": []any{true}},
+				"rename_rules": []any{"_"},
+			},
+			want: []ConfigIssue{
+				{Path: "albums.a", Message: "must be a string"},
+				{Path: "exclude", Message: "must be a list"},
+				{Path: "media.extensions[0]", Message: "must be a string"},
+				{Path: "recursive", Message: "must be a boolean"},
+				{Path: "rename_rules[0]", Message: "must be an object"},
+				{Path: "skip_levels", Message: "must be an integer"},
+			},
+		},
+		{
+			name:  "unknown fields",
+			value: map[string]any{"Recursive": true, "dates": map[string]any{"layout": "2006"}, "colour": "red"},
+			want: []ConfigIssue{
+				{Path: "Recursive", Message: `unknown field "Recursive", did you mean "recursive"?`},
+				{Path: "colour", Message: `unknown field "colour"`},
+				{Path: "dates.layout", Message: `unknown field "layout", did you mean "layouts"?`},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := checkValue(tt.value, options, ""); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("unexpected issues: %v (expected %v)", got, tt.want)
+			}
+		})
+	}
+}
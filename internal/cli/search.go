@@ -0,0 +1,173 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package cli
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/faabiosr/imt/internal/client"
+	"github.com/faabiosr/imt/internal/errors"
+)
+
+// searchPageSize is the number of assets fetched by search page.
+const searchPageSize = 500
+
+// smartSearchLimit is the number of assets fetched by a smart search without
+// limit, its results never end as every asset is ranked by relevance.
+const smartSearchLimit = 250
+
+// AssetSearch handles the filters to search assets. The query enables the
+// smart search, matching the assets by their content (CLIP), otherwise the
+// assets are searched by metadata. The limit caps the assets fetched, before
+// the path filters of the smart search are applied.
+type AssetSearch struct {
+	Query       string    `json:"query,omitempty"`
+	TakenAfter  time.Time `json:"taken_after,omitzero"`
+	TakenBefore time.Time `json:"taken_before,omitzero"`
+	Make        string    `json:"make,omitempty"`
+	Model       string    `json:"model,omitempty"`
+	City        string    `json:"city,omitempty"`
+	Country     string    `json:"country,omitempty"`
+	Type        string    `json:"type,omitempty" enum:"image,video"`
+	Favorite    *bool     `json:"favorite,omitempty"`
+	PathPrefix  string    `json:"path_prefix,omitempty"`
+	FileName    string    `json:"filename,omitempty"`
+	Limit       int       `json:"limit,omitempty"`
+}
+
+// validate checks the search filters.
+func (s *AssetSearch) validate() error {
+	if !s.TakenAfter.IsZero() && !s.TakenBefore.IsZero() && s.TakenAfter.After(s.TakenBefore) {
+		return errors.New("taken after must be before taken before")
+	}
+
+	if s.Limit < 0 {
+		return errors.New("limit must not be negative")
+	}
+
+	return (&MediaFilter{Type: s.Type}).validate()
+}
+
+// filtered reports whether any filter is set, the limit is not a filter.
+func (s *AssetSearch) filtered() bool {
+	c := *s
+	c.Limit = 0
+
+	return c != (AssetSearch{})
+}
+
+// limit returns the maximum number of assets fetched, zero means all of them.
+func (s *AssetSearch) limit() int {
+	if s.Limit == 0 && s.Query != "" {
+		return smartSearchLimit
+	}
+
+	return s.Limit
+}
+
+// body returns the request body of the search page.
+func (s *AssetSearch) body(page, size int) map[string]any {
+	body := map[string]any{
+		"page": page,
+		"size": size,
+	}
+
+	set := func(key string, value any, ok bool) {
+		if ok {
+			body[key] = value
+		}
+	}
+
+	set("query", s.Query, s.Query != "")
+	set("takenAfter", s.TakenAfter, !s.TakenAfter.IsZero())
+	set("takenBefore", s.TakenBefore, !s.TakenBefore.IsZero())
+	set("make", s.Make, s.Make != "")
+	set("model", s.Model, s.Model != "")
+	set("city", s.City, s.City != "")
+	set("country", s.Country, s.Country != "")
+	set("type", strings.ToUpper(s.Type), s.Type != "")
+	set("isFavorite", s.Favorite, s.Favorite != nil)
+
+	// the smart search has no path filters, they are applied on the results.
+	if s.Query == "" {
+		set("originalPath", s.PathPrefix, s.PathPrefix != "")
+		set("originalFileName", s.FileName, s.FileName != "")
+	}
+
+	return body
+}
+
+// match reports whether the asset matches the path prefix and the filename,
+// which are partial matches for the server.
+func (s *AssetSearch) match(a Asset) bool {
+	if s.PathPrefix != "" && !strings.HasPrefix(a.OriginalPath, s.PathPrefix) {
+		return false
+	}
+
+	return s.FileName == "" || strings.Contains(strings.ToLower(a.OriginalFileName), strings.ToLower(s.FileName))
+}
+
+// searchResponse represents a search results page.
+type searchResponse struct {
+	Assets struct {
+		Items    []Asset `json:"items"`
+		NextPage *string `json:"nextPage"`
+	} `json:"assets"`
+}
+
+// SearchAssets returns the assets matching the search, going through the
+// result pages until the limit is reached.
+func SearchAssets(ctx context.Context, cl *client.Client, s *AssetSearch) ([]Asset, error) {
+	if err := s.validate(); err != nil {
+		return nil, err
+	}
+
+	resource, _ := url.Parse("/api/search/metadata")
+	if s.Query != "" {
+		resource, _ = url.Parse("/api/search/smart")
+	}
+
+	assets := []Asset{}
+
+	limit, size := s.limit(), searchPageSize
+	if limit > 0 {
+		size = min(limit, searchPageSize)
+	}
+
+	for page := 1; page > 0; {
+		req, err := cl.NewRequest(ctx, http.MethodPost, resource, s.body(page, size))
+		if err != nil {
+			return assets, err
+		}
+
+		var res searchResponse
+		if err := cl.Do(req, &res); err != nil {
+			return assets, err
+		}
+
+		assets = append(assets, res.Assets.Items...)
+
+		if limit > 0 && len(assets) >= limit {
+			assets = assets[:limit]
+			break
+		}
+
+		page = 0
+		if res.Assets.NextPage != nil {
+			if page, err = strconv.Atoi(*res.Assets.NextPage); err != nil {
+				return assets, errors.Errorf("invalid search next page %q: %w", *res.Assets.NextPage, err)
+			}
+		}
+	}
+
+	return slices.DeleteFunc(assets, func(a Asset) bool { return !s.match(a) }), nil
+}
@@ -0,0 +1,208 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+
+	"github.com/faabiosr/imt/internal/client"
+)
+
+func TestSearch_SearchAssets(t *testing.T) {
+	t.Run("invalid filters", func(t *testing.T) {
+		searches := []*AssetSearch{
+			{Type: "audio"},
+			{TakenAfter: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), TakenBefore: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)},
+			{Query: "beach", Limit: -1},
+		}
+
+		for _, s := range searches {
+			if _, err := SearchAssets(context.Background(), nil, s); err == nil {
+				t.Errorf("expected an error for %+v, got nil", s)
+			}
+		}
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		hc := http.DefaultClient
+
+		httpmock.ActivateNonDefault(hc)
+		defer httpmock.DeactivateAndReset()
+
+		httpmock.RegisterResponder(
+			http.MethodPost,
+			testHost+"/api/search/metadata",
+			httpmock.NewJsonResponderOrPanic(http.StatusBadRequest, json.RawMessage(`{"message": "invalid search"}`)),
+		)
+
+		baseURL, _ := url.Parse(testHost)
+		cl := client.NewWithHTTPClient(baseURL, testAPIKey, hc)
+
+		if _, err := SearchAssets(context.Background(), cl, &AssetSearch{City: "Lisbon"}); err == nil {
+			t.Error("expected an error, got nil")
+		}
+	})
+
+	t.Run("metadata search pages", func(t *testing.T) {
+		hc := http.DefaultClient
+
+		httpmock.ActivateNonDefault(hc)
+		defer httpmock.DeactivateAndReset()
+
+		var bodies []map[string]any
+
+		pages := []string{
+			`{"assets": {"items": [{"id": "1", "originalPath": "/photos/2023/a.jpg"}], "nextPage": "2"}}`,
+			`{"assets": {"items": [{"id": "2", "originalPath": "/photos/2023/b.jpg"}, {"id": "3", "originalPath": "/old/photos/2023/c.jpg"}], "nextPage": null}}`,
+		}
+
+		httpmock.RegisterResponder(
+			http.MethodPost,
+			testHost+"/api/search/metadata",
+			func(req *http.Request) (*http.Response, error) {
+				var body map[string]any
+				if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+					return nil, err
+				}
+
+				bodies = append(bodies, body)
+
+				return httpmock.NewStringResponse(http.StatusOK, pages[len(bodies)-1]), nil
+			},
+		)
+
+		baseURL, _ := url.Parse(testHost)
+		cl := client.NewWithHTTPClient(baseURL, testAPIKey, hc)
+
+		favorite := true
+
+		s := &AssetSearch{
+			City:       "Lisbon",
+			Type:       MediaImage,
+			Favorite:   &favorite,
+			TakenAfter: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+			PathPrefix: "/photos/",
+		}
+
+		assets, err := SearchAssets(context.Background(), cl, s)
+		if err != nil {
+			t.Fatalf("expected nil, got %v", err)
+		}
+
+		if ids := assetIDs(assets); !reflect.DeepEqual(ids, []string{"1", "2"}) {
+			t.Errorf("unexpected assets: %v (expected [1 2])", ids)
+		}
+
+		expected := map[string]any{
+			"page":         float64(2),
+			"size":         float64(searchPageSize),
+			"city":         "Lisbon",
+			"type":         "IMAGE",
+			"isFavorite":   true,
+			"takenAfter":   "2023-01-01T00:00:00Z",
+			"originalPath": "/photos/",
+		}
+
+		if len(bodies) != 2 || !reflect.DeepEqual(bodies[1], expected) {
+			t.Errorf("unexpected request bodies: %v (expected last %v)", bodies, expected)
+		}
+	})
+
+	t.Run("smart search", func(t *testing.T) {
+		hc := http.DefaultClient
+
+		httpmock.ActivateNonDefault(hc)
+		defer httpmock.DeactivateAndReset()
+
+		var body map[string]any
+
+		httpmock.RegisterResponder(
+			http.MethodPost,
+			testHost+"/api/search/smart",
+			func(req *http.Request) (*http.Response, error) {
+				if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+					return nil, err
+				}
+
+				return httpmock.NewStringResponse(http.StatusOK, `{"assets": {"items": [
+					{"id": "1", "originalFileName": "IMG_0001.JPG"},
+					{"id": "2", "originalFileName": "DSC_0002.JPG"}
+				]}}`), nil
+			},
+		)
+
+		baseURL, _ := url.Parse(testHost)
+		cl := client.NewWithHTTPClient(baseURL, testAPIKey, hc)
+
+		assets, err := SearchAssets(context.Background(), cl, &AssetSearch{Query: "beach sunset", FileName: "img_"})
+		if err != nil {
+			t.Fatalf("expected nil, got %v", err)
+		}
+
+		if ids := assetIDs(assets); !reflect.DeepEqual(ids, []string{"1"}) {
+			t.Errorf("unexpected assets: %v (expected [1])", ids)
+		}
+
+		if body["query"] != "beach sunset" || body["originalFileName"] != nil || body["size"] != float64(smartSearchLimit) {
+			t.Errorf("unexpected request body: %v", body)
+		}
+	})
+
+	t.Run("smart search limit", func(t *testing.T) {
+		hc := http.DefaultClient
+
+		httpmock.ActivateNonDefault(hc)
+		defer httpmock.DeactivateAndReset()
+
+		var bodies []map[string]any
+
+		// the smart search always has a next page.
+		httpmock.RegisterResponder(
+			http.MethodPost,
+			testHost+"/api/search/smart",
+			func(req *http.Request) (*http.Response, error) {
+				var body map[string]any
+				if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+					return nil, err
+				}
+
+				bodies = append(bodies, body)
+				n := len(bodies)
+
+				return httpmock.NewJsonResponse(http.StatusOK, map[string]any{"assets": map[string]any{
+					"items":    []Asset{{ID: fmt.Sprintf("%d-1", n)}, {ID: fmt.Sprintf("%d-2", n)}},
+					"nextPage": strconv.Itoa(n + 1),
+				}})
+			},
+		)
+
+		baseURL, _ := url.Parse(testHost)
+		cl := client.NewWithHTTPClient(baseURL, testAPIKey, hc)
+
+		assets, err := SearchAssets(context.Background(), cl, &AssetSearch{Query: "beach sunset", Limit: 3})
+		if err != nil {
+			t.Fatalf("expected nil, got %v", err)
+		}
+
+		if ids := assetIDs(assets); !reflect.DeepEqual(ids, []string{"1-1", "1-2", "2-1"}) {
+			t.Errorf("unexpected assets: %v (expected [1-1 1-2 2-1])", ids)
+		}
+
+		if len(bodies) != 2 || bodies[1]["page"] != float64(2) || bodies[1]["size"] != float64(3) {
+			t.Errorf("unexpected request bodies: %v (expected 2 pages of size 3)", bodies)
+		}
+	})
+}
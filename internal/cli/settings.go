@@ -0,0 +1,310 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/user"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+
+	"github.com/faabiosr/imt/internal/errors"
+)
+
+// Setting sources.
+const (
+	SettingSourceEnv    = "env"
+	SettingSourceConfig = "config"
+)
+
+// settingsEnvPrefix is the prefix of the settings environment variables.
+const settingsEnvPrefix = "IMT_"
+
+// Setting is a setting value and where it comes from.
+type Setting struct {
+	Key    string
+	Values []string
+	Source string
+}
+
+// Settings holds the user defaults of the command flags. Keys are flag names,
+// global to every command, or prefixed by the command path, like "exclude" and
+// "album.auto-create.exclude". Environment variables override the values of
+// the settings file.
+type Settings struct {
+	name string
+	doc  map[string]any
+}
+
+// DefaultSettingsPath returns the path of the settings file, inside the
+// XDG_CONFIG_HOME folder or ~/.config when not set. An existing YAML file is
+// preferred over the JSON default.
+func DefaultSettingsPath() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+
+	name := filepath.Join(dir, "config.json")
+
+	for _, ext := range []string{".json", ".yaml", ".yml"} {
+		candidate := strings.TrimSuffix(name, ".json") + ext
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate, nil
+		}
+	}
+
+	return name, nil
+}
+
+// configDir returns the imt folder inside the XDG_CONFIG_HOME folder, or
+// ~/.config when not set.
+func configDir() (string, error) {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		u, err := user.Current()
+		if err != nil {
+			return "", fmt.Errorf("unable to retrieve user information: %w", err)
+		}
+
+		dir = filepath.Join(u.HomeDir, ".config")
+	}
+
+	return filepath.Join(dir, "imt"), nil
+}
+
+// LoadSettings reads the settings file, a missing file results in empty
+// settings.
+func LoadSettings(name string) (*Settings, error) {
+	s := &Settings{name: filepath.Clean(name), doc: map[string]any{}}
+
+	content, err := os.ReadFile(s.name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("unable to read settings file: %w", err)
+	}
+
+	f, err := decodeConfigFile(content, filepath.Ext(s.name))
+	if err != nil {
+		return nil, fmt.Errorf("invalid settings file %s: %w", s.name, err)
+	}
+
+	s.doc = f.doc
+
+	return s, nil
+}
+
+// SettingEnv returns the environment variable of the setting key, like
+// IMT_ALBUM_AUTO_CREATE_EXCLUDE.
+func SettingEnv(key string) string {
+	return settingsEnvPrefix + strings.ToUpper(strings.NewReplacer(".", "_", "-", "_").Replace(key))
+}
+
+// Get returns the setting of the key, the environment variable takes
+// precedence over the settings file.
+func (s *Settings) Get(key string) (Setting, bool) {
+	if v, ok := s.env(key); ok {
+		return v, true
+	}
+
+	return s.file(key)
+}
+
+// Lookup resolves the setting of a flag of the command path. Environment
+// variables take precedence over the settings file, and command settings take
+// precedence over global ones.
+func (s *Settings) Lookup(command []string, flag string) (Setting, bool) {
+	keys := []string{flag}
+	if len(command) > 0 {
+		keys = []string{strings.Join(append(slices.Clone(command), flag), "."), flag}
+	}
+
+	for _, get := range []func(string) (Setting, bool){s.env, s.file} {
+		for _, key := range keys {
+			if v, ok := get(key); ok {
+				return v, true
+			}
+		}
+	}
+
+	return Setting{}, false
+}
+
+// List returns the settings of the keys and of the settings file, sorted by key.
+func (s *Settings) List(keys []string) []Setting {
+	keys = slices.Clone(keys)
+
+	flattenSettings(s.doc, "", func(key string, _ []string) {
+		keys = append(keys, key)
+	})
+
+	slices.Sort(keys)
+
+	settings := []Setting{}
+
+	for _, key := range slices.Compact(keys) {
+		if v, ok := s.Get(key); ok {
+			settings = append(settings, v)
+		}
+	}
+
+	return settings
+}
+
+// Set stores the values of the key, a single value is stored as a string.
+func (s *Settings) Set(key string, values []string) error {
+	if len(values) == 0 {
+		return errors.Errorf("setting %q requires a value", key)
+	}
+
+	parts := strings.Split(key, ".")
+	if slices.Contains(parts, "") {
+		return errors.Errorf("invalid setting %q", key)
+	}
+
+	doc := s.doc
+
+	for i, part := range parts[:len(parts)-1] {
+		v, ok := doc[part]
+		if !ok {
+			v = map[string]any{}
+			doc[part] = v
+		}
+
+		m, ok := v.(map[string]any)
+		if !ok {
+			return errors.Errorf("setting %q conflicts with %q", key, strings.Join(parts[:i+1], "."))
+		}
+
+		doc = m
+	}
+
+	last := parts[len(parts)-1]
+	if _, ok := doc[last].(map[string]any); ok {
+		return errors.Errorf("setting %q conflicts with the settings of command %q", key, key)
+	}
+
+	if len(values) == 1 {
+		doc[last] = values[0]
+		return nil
+	}
+
+	list := make([]any, len(values))
+	for i, v := range values {
+		list[i] = v
+	}
+
+	doc[last] = list
+
+	return nil
+}
+
+// Save writes the settings file, creating its folder when needed.
+func (s *Settings) Save() error {
+	var (
+		content []byte
+		err     error
+	)
+
+	switch ext := strings.ToLower(filepath.Ext(s.name)); ext {
+	case ".json":
+		content, err = json.MarshalIndent(s.doc, "", "  ")
+		content = append(content, '\n')
+	case ".yaml", ".yml":
+		content, err = yaml.Marshal(s.doc)
+	case ".toml":
+		content, err = toml.Marshal(s.doc)
+	default:
+		return errors.Errorf("unsupported settings file extension %q, must be .json, .yaml, .yml or .toml", ext)
+	}
+
+	if err != nil {
+		return fmt.Errorf("unable to encode settings: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.name), perm); err != nil {
+		return fmt.Errorf("failed to create config folder: %w", err)
+	}
+
+	return os.WriteFile(s.name, content, 0o600)
+}
+
+// env returns the setting of the key from its environment variable.
+func (s *Settings) env(key string) (Setting, bool) {
+	v, ok := os.LookupEnv(SettingEnv(key))
+	if !ok {
+		return Setting{}, false
+	}
+
+	return Setting{Key: key, Values: []string{v}, Source: SettingSourceEnv}, true
+}
+
+// file returns the setting of the key from the settings file.
+func (s *Settings) file(key string) (Setting, bool) {
+	var v any = s.doc
+
+	for _, part := range strings.Split(key, ".") {
+		m, ok := v.(map[string]any)
+		if !ok {
+			return Setting{}, false
+		}
+
+		if v, ok = m[part]; !ok {
+			return Setting{}, false
+		}
+	}
+
+	values, ok := settingValues(v)
+	if !ok {
+		return Setting{}, false
+	}
+
+	return Setting{Key: key, Values: values, Source: SettingSourceConfig}, true
+}
+
+// flattenSettings calls fn for every setting of the document.
+func flattenSettings(doc map[string]any, prefix string, fn func(key string, values []string)) {
+	for k, v := range doc {
+		key := joinPath(prefix, k)
+
+		if m, ok := v.(map[string]any); ok {
+			flattenSettings(m, key, fn)
+			continue
+		}
+
+		if values, ok := settingValues(v); ok {
+			fn(key, values)
+		}
+	}
+}
+
+// settingValues converts a setting value into strings, commands and empty
+// values have no values.
+func settingValues(v any) ([]string, bool) {
+	switch v := v.(type) {
+	case nil, map[string]any:
+		return nil, false
+	case []any:
+		values := make([]string, len(v))
+		for i, item := range v {
+			values[i] = fmt.Sprint(item)
+		}
+
+		return values, true
+	default:
+		return []string{fmt.Sprint(v)}, true
+	}
+}
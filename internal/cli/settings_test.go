@@ -0,0 +1,161 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSettings(t *testing.T) {
+	t.Run("default path", func(t *testing.T) {
+		tmp := t.TempDir()
+		t.Setenv("XDG_CONFIG_HOME", tmp)
+
+		name, err := DefaultSettingsPath()
+		if err != nil {
+			t.Errorf("expected nil, got %v", err)
+		}
+
+		if expected := filepath.Join(tmp, "imt", "config.json"); name != expected {
+			t.Errorf("unexpected path: %s (expected %s)", name, expected)
+		}
+
+		expected := filepath.Join(tmp, "imt", "config.yaml")
+
+		if err := os.MkdirAll(filepath.Dir(expected), perm); err != nil {
+			t.Fatalf("expected nil, got %v", err)
+		}
+
+		if err := os.WriteFile(expected, []byte("recursive: true\n"), 0o600); err != nil {
+			t.Fatalf("expected nil, got %v", err)
+		}
+
+		if name, _ := DefaultSettingsPath(); name != expected {
+			t.Errorf("unexpected path: %s (expected %s)", name, expected)
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		s, err := LoadSettings(filepath.Join(t.TempDir(), "config.json"))
+		if err != nil {
+			t.Errorf("expected nil, got %v", err)
+		}
+
+		if v := s.List(nil); len(v) != 0 {
+			t.Errorf("unexpected settings: %v", v)
+		}
+	})
+
+	t.Run("invalid file", func(t *testing.T) {
+		name := filepath.Join(t.TempDir(), "config.yaml")
+
+		if err := os.WriteFile(name, []byte("exclude: [\n"), 0o600); err != nil {
+			t.Fatalf("expected nil, got %v", err)
+		}
+
+		if _, err := LoadSettings(name); err == nil {
+			t.Error("expected an error, got nil")
+		}
+	})
+
+	t.Run("set conflicts", func(t *testing.T) {
+		s, _ := LoadSettings(filepath.Join(t.TempDir(), "config.json"))
+
+		if err := s.Set("album.auto-create.exclude", []string{"Raw"}); err != nil {
+			t.Errorf("expected nil, got %v", err)
+		}
+
+		for _, key := range []string{"album", "album.auto-create.exclude.raw", "album..exclude"} {
+			if err := s.Set(key, []string{"Raw"}); err == nil {
+				t.Errorf("expected an error for %s, got nil", key)
+			}
+		}
+
+		if err := s.Set("exclude", nil); err == nil {
+			t.Error("expected an error, got nil")
+		}
+	})
+
+	t.Run("save and lookup", func(t *testing.T) {
+		for _, ext := range []string{".json", ".yaml", ".toml"} {
+			name := filepath.Join(t.TempDir(), "imt", "config"+ext)
+
+			s, _ := LoadSettings(name)
+
+			if err := s.Set("exclude", []string{"@eaDir"}); err != nil {
+				t.Errorf("expected nil, got %v", err)
+			}
+
+			if err := s.Set("album.auto-create.exclude", []string{"Raw", "Tmp"}); err != nil {
+				t.Errorf("expected nil, got %v", err)
+			}
+
+			if err := s.Set("skip-levels", []string{"2"}); err != nil {
+				t.Errorf("expected nil, got %v", err)
+			}
+
+			if err := s.Save(); err != nil {
+				t.Errorf("expected nil, got %v", err)
+			}
+
+			s, err := LoadSettings(name)
+			if err != nil {
+				t.Errorf("expected nil, got %v", err)
+			}
+
+			command := []string{"album", "auto-create"}
+
+			v, _ := s.Lookup(command, "exclude")
+			if expected := []string{"Raw", "Tmp"}; !reflect.DeepEqual(v.Values, expected) {
+				t.Errorf("unexpected %s values: %v (expected %v)", ext, v.Values, expected)
+			}
+
+			v, _ = s.Lookup([]string{"album", "list"}, "exclude")
+			if expected := []string{"@eaDir"}; !reflect.DeepEqual(v.Values, expected) {
+				t.Errorf("unexpected %s values: %v (expected %v)", ext, v.Values, expected)
+			}
+
+			if _, ok := s.Lookup(command, "recursive"); ok {
+				t.Errorf("unexpected %s recursive setting", ext)
+			}
+
+			if n := len(s.List(nil)); n != 3 {
+				t.Errorf("unexpected %s number of settings: %d (expected 3)", ext, n)
+			}
+		}
+	})
+
+	t.Run("environment precedence", func(t *testing.T) {
+		s, _ := LoadSettings(filepath.Join(t.TempDir(), "config.json"))
+		_ = s.Set("album.auto-create.skip-levels", []string{"2"})
+
+		t.Setenv("IMT_SKIP_LEVELS", "3")
+
+		v, _ := s.Lookup([]string{"album", "auto-create"}, "skip-levels")
+		if v.Source != SettingSourceEnv || v.Values[0] != "3" {
+			t.Errorf("unexpected setting: %v (expected IMT_SKIP_LEVELS)", v)
+		}
+
+		t.Setenv("IMT_ALBUM_AUTO_CREATE_SKIP_LEVELS", "4")
+
+		v, _ = s.Lookup([]string{"album", "auto-create"}, "skip-levels")
+		if v.Values[0] != "4" {
+			t.Errorf("unexpected setting: %v (expected IMT_ALBUM_AUTO_CREATE_SKIP_LEVELS)", v)
+		}
+
+		expected := []Setting{
+			{Key: "album.auto-create.skip-levels", Values: []string{"4"}, Source: SettingSourceEnv},
+			{Key: "skip-levels", Values: []string{"3"}, Source: SettingSourceEnv},
+		}
+
+		if v := s.List([]string{"skip-levels", "recursive"}); !reflect.DeepEqual(v, expected) {
+			t.Errorf("unexpected settings: %v (expected %v)", v, expected)
+		}
+	})
+}
@@ -0,0 +1,268 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/faabiosr/imt/internal/client"
+	"github.com/faabiosr/imt/internal/errors"
+)
+
+// Roles of the users an album is shared with, the owner role is only
+// reported.
+const (
+	ShareRoleEditor = "editor"
+	ShareRoleViewer = "viewer"
+	ShareRoleOwner  = "owner"
+)
+
+// User represents an Immich user.
+type User struct {
+	ID    string `json:"id"`
+	Email string `json:"email"`
+	Name  string `json:"name"`
+}
+
+// AlbumMember represents the owner or a user an album is shared with.
+type AlbumMember struct {
+	User
+	Role string
+}
+
+// ShareAlbumReport holds the outcome of an album sharing.
+type ShareAlbumReport struct {
+	Added   int
+	Updated int
+}
+
+// albumShare is a user email and the role the album is shared with.
+type albumShare struct {
+	email string
+	role  string
+}
+
+// parseAlbumShares parses the shares formatted as email[:editor|viewer], the
+// role defaults to viewer.
+func parseAlbumShares(specs []string) ([]albumShare, error) {
+	shares := make([]albumShare, 0, len(specs))
+
+	for _, spec := range specs {
+		email, role, ok := strings.Cut(spec, ":")
+		if !ok {
+			role = ShareRoleViewer
+		}
+
+		if email = strings.TrimSpace(email); email == "" {
+			return nil, errors.Errorf("invalid share %q, must be formatted as email[:editor|viewer]", spec)
+		}
+
+		if role != ShareRoleEditor && role != ShareRoleViewer {
+			return nil, errors.Errorf("invalid share role %q of %q, must be editor or viewer", role, email)
+		}
+
+		shares = append(shares, albumShare{email: email, role: role})
+	}
+
+	return shares, nil
+}
+
+// FetchUsers returns all users.
+func FetchUsers(ctx context.Context, cl *client.Client) ([]User, error) {
+	resource, _ := url.Parse("/api/users")
+
+	users := []User{}
+
+	req, err := cl.NewRequest(ctx, http.MethodGet, resource, nil)
+	if err != nil {
+		return users, err
+	}
+
+	return users, cl.Do(req, &users)
+}
+
+// findUser returns the user of the email, ignoring case.
+func findUser(users []User, email string) (User, error) {
+	i := slices.IndexFunc(users, func(u User) bool { return strings.EqualFold(u.Email, email) })
+	if i < 0 {
+		return User{}, errors.Errorf("user %q not found", email)
+	}
+
+	return users[i], nil
+}
+
+// resolveAlbumShares resolves the users of the shares, as album users.
+func resolveAlbumShares(ctx context.Context, cl *client.Client, shares []albumShare) ([]map[string]string, error) {
+	users, err := FetchUsers(ctx, cl)
+	if err != nil {
+		return nil, err
+	}
+
+	albumUsers := make([]map[string]string, 0, len(shares))
+
+	for _, s := range shares {
+		u, err := findUser(users, s.email)
+		if err != nil {
+			return nil, err
+		}
+
+		albumUsers = append(albumUsers, map[string]string{"userId": u.ID, "role": s.role})
+	}
+
+	return albumUsers, nil
+}
+
+// ShareAlbum shares the album, referenced by name or ID, with the users of
+// the shares formatted as email[:editor|viewer]. The role of the users the
+// album is already shared with is updated.
+func ShareAlbum(ctx context.Context, cl *client.Client, ref string, specs []string) (*ShareAlbumReport, error) {
+	report := &ShareAlbumReport{}
+
+	shares, err := parseAlbumShares(specs)
+	if err != nil {
+		return report, err
+	}
+
+	if len(shares) == 0 {
+		return report, errors.New("at least one user is required")
+	}
+
+	album, err := findAlbumDetails(ctx, cl, ref)
+	if err != nil {
+		return report, err
+	}
+
+	albumUsers, err := resolveAlbumShares(ctx, cl, shares)
+	if err != nil {
+		return report, err
+	}
+
+	added := []map[string]string{}
+
+	for i, u := range albumUsers {
+		if u["userId"] == album.OwnerID {
+			return report, errors.Errorf("user %q owns the album", shares[i].email)
+		}
+
+		j := slices.IndexFunc(album.AlbumUsers, func(au albumUser) bool { return au.User.ID == u["userId"] })
+		if j < 0 {
+			added = append(added, u)
+			continue
+		}
+
+		if album.AlbumUsers[j].Role == u["role"] {
+			continue
+		}
+
+		resource, _ := url.Parse(fmt.Sprintf("/api/albums/%s/user/%s", album.ID, u["userId"]))
+
+		req, err := cl.NewRequest(ctx, http.MethodPut, resource, map[string]string{"role": u["role"]})
+		if err != nil {
+			return report, err
+		}
+
+		if err := cl.Do(req, nil); err != nil {
+			return report, err
+		}
+
+		report.Updated++
+	}
+
+	if len(added) == 0 {
+		return report, nil
+	}
+
+	if err := addAlbumUsers(ctx, cl, album.ID, added); err != nil {
+		return report, err
+	}
+
+	report.Added = len(added)
+
+	return report, nil
+}
+
+// UnshareAlbum stops sharing the album, referenced by name or ID, with the
+// users of the emails.
+func UnshareAlbum(ctx context.Context, cl *client.Client, ref string, emails []string) error {
+	if len(emails) == 0 {
+		return errors.New("at least one user is required")
+	}
+
+	album, err := findAlbumDetails(ctx, cl, ref)
+	if err != nil {
+		return err
+	}
+
+	for _, email := range emails {
+		i := slices.IndexFunc(album.AlbumUsers, func(au albumUser) bool { return strings.EqualFold(au.User.Email, email) })
+		if i < 0 {
+			return errors.Errorf("album is not shared with user %q", email)
+		}
+
+		resource, _ := url.Parse(fmt.Sprintf("/api/albums/%s/user/%s", album.ID, album.AlbumUsers[i].User.ID))
+
+		req, err := cl.NewRequest(ctx, http.MethodDelete, resource, nil)
+		if err != nil {
+			return err
+		}
+
+		if err := cl.Do(req, nil); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// AlbumMembers returns the owner and the users the album, referenced by name
+// or ID, is shared with.
+func AlbumMembers(ctx context.Context, cl *client.Client, ref string) ([]AlbumMember, error) {
+	album, err := findAlbumDetails(ctx, cl, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	members := []AlbumMember{{User: album.Owner, Role: ShareRoleOwner}}
+
+	for _, au := range album.AlbumUsers {
+		members = append(members, AlbumMember{User: au.User, Role: au.Role})
+	}
+
+	return members, nil
+}
+
+// findAlbumDetails returns the album, referenced by name or ID, with its
+// assets and shared users.
+func findAlbumDetails(ctx context.Context, cl *client.Client, ref string) (*albumDetails, error) {
+	as, err := FetchAlbums(ctx, cl)
+	if err != nil {
+		return nil, err
+	}
+
+	a, err := as.find(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	return fetchAlbum(ctx, cl, a.ID)
+}
+
+// addAlbumUsers shares an album with a list of users and their roles.
+func addAlbumUsers(ctx context.Context, cl *client.Client, id string, users []map[string]string) error {
+	resource, _ := url.Parse(fmt.Sprintf("/api/albums/%s/users", id))
+
+	req, err := cl.NewRequest(ctx, http.MethodPut, resource, map[string]any{"albumUsers": users})
+	if err != nil {
+		return err
+	}
+
+	return cl.Do(req, nil)
+}
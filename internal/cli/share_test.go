@@ -0,0 +1,240 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+
+	"github.com/faabiosr/imt/internal/client"
+)
+
+const testUsers = `[
+	{"id": "u1", "email": "owner@example.com", "name": "Owner"},
+	{"id": "u2", "email": "ana@example.com", "name": "Ana"},
+	{"id": "u3", "email": "rui@example.com", "name": "Rui"}
+]`
+
+const testSharedAlbum = `{
+	"id": "a1",
+	"albumName": "Family",
+	"ownerId": "u1",
+	"owner": {"id": "u1", "email": "owner@example.com", "name": "Owner"},
+	"albumUsers": [{"user": {"id": "u2", "email": "ana@example.com", "name": "Ana"}, "role": "viewer"}]
+}`
+
+func TestShare_parseAlbumShares(t *testing.T) {
+	shares, err := parseAlbumShares([]string{"ana@example.com", "rui@example.com:editor"})
+	if err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+
+	expected := []albumShare{{email: "ana@example.com", role: ShareRoleViewer}, {email: "rui@example.com", role: ShareRoleEditor}}
+
+	if !reflect.DeepEqual(shares, expected) {
+		t.Errorf("unexpected shares: %v (expected %v)", shares, expected)
+	}
+
+	for _, spec := range []string{":editor", "ana@example.com:owner"} {
+		if _, err := parseAlbumShares([]string{spec}); err == nil {
+			t.Errorf("expected an error for %q, got nil", spec)
+		}
+	}
+}
+
+func TestShare_ShareAlbum(t *testing.T) {
+	setup := func() *client.Client {
+		hc := http.DefaultClient
+
+		httpmock.ActivateNonDefault(hc)
+
+		httpmock.RegisterResponder(
+			http.MethodGet,
+			testHost+"/api/albums",
+			httpmock.NewStringResponder(http.StatusOK, `[{"id": "a1", "albumName": "Family"}]`),
+		)
+		httpmock.RegisterResponder(http.MethodGet, testHost+"/api/albums/a1", httpmock.NewStringResponder(http.StatusOK, testSharedAlbum))
+		httpmock.RegisterResponder(http.MethodGet, testHost+"/api/users", httpmock.NewStringResponder(http.StatusOK, testUsers))
+
+		baseURL, _ := url.Parse(testHost)
+
+		return client.NewWithHTTPClient(baseURL, testAPIKey, hc)
+	}
+
+	t.Run("invalid users", func(t *testing.T) {
+		cl := setup()
+		defer httpmock.DeactivateAndReset()
+
+		for _, specs := range [][]string{nil, {"bob@example.com"}, {"owner@example.com"}} {
+			if _, err := ShareAlbum(context.Background(), cl, "Family", specs); err == nil {
+				t.Errorf("expected an error for %v, got nil", specs)
+			}
+		}
+	})
+
+	t.Run("success", func(t *testing.T) {
+		cl := setup()
+		defer httpmock.DeactivateAndReset()
+
+		bodies := map[string]any{}
+
+		record := func(req *http.Request) (*http.Response, error) {
+			var body any
+			if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+				return nil, err
+			}
+
+			bodies[req.Method+" "+req.URL.Path] = body
+
+			return httpmock.NewStringResponse(http.StatusOK, `{}`), nil
+		}
+
+		httpmock.RegisterResponder(http.MethodPut, testHost+"/api/albums/a1/users", record)
+		httpmock.RegisterResponder(http.MethodPut, testHost+"/api/albums/a1/user/u2", record)
+
+		report, err := ShareAlbum(context.Background(), cl, "Family", []string{"ANA@example.com:editor", "rui@example.com"})
+		if err != nil {
+			t.Fatalf("expected nil, got %v", err)
+		}
+
+		if expected := (&ShareAlbumReport{Added: 1, Updated: 1}); !reflect.DeepEqual(report, expected) {
+			t.Errorf("unexpected report: %+v (expected %+v)", report, expected)
+		}
+
+		expected := map[string]any{
+			"PUT /api/albums/a1/users": map[string]any{"albumUsers": []any{
+				map[string]any{"userId": "u3", "role": "viewer"},
+			}},
+			"PUT /api/albums/a1/user/u2": map[string]any{"role": "editor"},
+		}
+
+		if !reflect.DeepEqual(bodies, expected) {
+			t.Errorf("unexpected requests: %v (expected %v)", bodies, expected)
+		}
+	})
+
+	t.Run("unshare", func(t *testing.T) {
+		cl := setup()
+		defer httpmock.DeactivateAndReset()
+
+		httpmock.RegisterResponder(http.MethodDelete, testHost+"/api/albums/a1/user/u2", httpmock.NewStringResponder(http.StatusNoContent, ""))
+
+		if err := UnshareAlbum(context.Background(), cl, "Family", []string{"rui@example.com"}); err == nil {
+			t.Error("expected an error, got nil")
+		}
+
+		if err := UnshareAlbum(context.Background(), cl, "Family", []string{"ana@example.com"}); err != nil {
+			t.Errorf("expected nil, got %v", err)
+		}
+
+		if n := httpmock.GetCallCountInfo()["DELETE "+testHost+"/api/albums/a1/user/u2"]; n != 1 {
+			t.Errorf("unexpected users removed: %d (expected 1)", n)
+		}
+	})
+
+	t.Run("members", func(t *testing.T) {
+		cl := setup()
+		defer httpmock.DeactivateAndReset()
+
+		members, err := AlbumMembers(context.Background(), cl, "a1")
+		if err != nil {
+			t.Fatalf("expected nil, got %v", err)
+		}
+
+		expected := []AlbumMember{
+			{User: User{ID: "u1", Email: "owner@example.com", Name: "Owner"}, Role: ShareRoleOwner},
+			{User: User{ID: "u2", Email: "ana@example.com", Name: "Ana"}, Role: ShareRoleViewer},
+		}
+
+		if !reflect.DeepEqual(members, expected) {
+			t.Errorf("unexpected members: %+v (expected %+v)", members, expected)
+		}
+	})
+}
+
+func TestShare_AutoCreateAlbums(t *testing.T) {
+	hc := http.DefaultClient
+
+	httpmock.ActivateNonDefault(hc)
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder(
+		http.MethodGet,
+		testHost+"/api/view/folder/unique-paths",
+		httpmock.NewStringResponder(http.StatusOK, `["/external/2025/food", "/external/2025/trip"]`),
+	)
+	httpmock.RegisterResponder(
+		http.MethodGet,
+		testHost+"/api/albums",
+		httpmock.NewStringResponder(http.StatusOK, `[{"id": "a1", "albumName": "food"}]`),
+	)
+	httpmock.RegisterResponder(
+		http.MethodGet,
+		testHost+"/api/view/folder",
+		httpmock.NewStringResponder(http.StatusOK, `[{"id": "1"}]`),
+	)
+	httpmock.RegisterResponder(http.MethodGet, testHost+"/api/users", httpmock.NewStringResponder(http.StatusOK, testUsers))
+	httpmock.RegisterResponder(
+		http.MethodPost,
+		testHost+"/api/albums",
+		func(req *http.Request) (*http.Response, error) {
+			var body map[string]string
+			if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+				return nil, err
+			}
+
+			return httpmock.NewJsonResponse(http.StatusCreated, Album{ID: "new-" + body["albumName"], Name: body["albumName"]})
+		},
+	)
+	httpmock.RegisterResponder(http.MethodPut, `=~^`+testHost+`/api/albums/([\w-]+)/assets`, httpmock.NewStringResponder(http.StatusOK, `[]`))
+
+	var shared []any
+
+	httpmock.RegisterResponder(
+		http.MethodPut,
+		`=~^`+testHost+`/api/albums/([\w-]+)/users`,
+		func(req *http.Request) (*http.Response, error) {
+			var body map[string]any
+			if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+				return nil, err
+			}
+
+			shared = append(shared, req.URL.Path, body["albumUsers"])
+
+			return httpmock.NewStringResponse(http.StatusOK, `{}`), nil
+		},
+	)
+
+	baseURL, _ := url.Parse(testHost)
+	cl := client.NewWithHTTPClient(baseURL, testAPIKey, hc)
+
+	opts := &AutoCreateAlbumsOptions{
+		Folder: "/external/2025/",
+		Remote: true,
+		Share:  []string{"ana@example.com:editor", "rui@example.com"},
+	}
+
+	if _, err := AutoCreateAlbums(context.Background(), cl, opts); err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+
+	users := []any{
+		map[string]any{"userId": "u2", "role": "editor"},
+		map[string]any{"userId": "u3", "role": "viewer"},
+	}
+
+	expected := []any{"/api/albums/new-2025/users", users, "/api/albums/new-trip/users", users}
+
+	if !reflect.DeepEqual(shared, expected) {
+		t.Errorf("unexpected albums shared: %v (expected %v)", shared, expected)
+	}
+}
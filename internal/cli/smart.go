@@ -0,0 +1,257 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/faabiosr/imt/internal/client"
+	"github.com/faabiosr/imt/internal/errors"
+)
+
+// SmartAlbum is an album defined by an asset search instead of folders.
+type SmartAlbum struct {
+	Name   string      `json:"name"`
+	Search AssetSearch `json:"search"`
+}
+
+// SmartAlbumReport holds the outcome of a smart album refresh.
+type SmartAlbumReport struct {
+	Album   string
+	Created bool
+	Added   int
+	Removed int
+	Err     error
+}
+
+// DefaultSmartAlbumsPath returns the path of the smart albums definitions
+// file, next to the settings file.
+func DefaultSmartAlbumsPath() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "smart_albums.json"), nil
+}
+
+// LoadSmartAlbums reads the smart albums definitions file, a missing file
+// results in no definitions.
+func LoadSmartAlbums(name string) ([]SmartAlbum, error) {
+	albums := []SmartAlbum{}
+
+	content, err := os.ReadFile(filepath.Clean(name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return albums, nil
+	}
+
+	if err != nil {
+		return albums, fmt.Errorf("unable to read smart albums file: %w", err)
+	}
+
+	if err := json.Unmarshal(content, &albums); err != nil {
+		return albums, fmt.Errorf("invalid smart albums file %s: %w", name, err)
+	}
+
+	return albums, nil
+}
+
+// SaveSmartAlbums writes the smart albums definitions file, creating its
+// folder when needed.
+func SaveSmartAlbums(name string, albums []SmartAlbum) error {
+	content, err := json.MarshalIndent(albums, "", "  ")
+	if err != nil {
+		return fmt.Errorf("unable to encode smart albums: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(name), perm); err != nil {
+		return fmt.Errorf("failed to create config folder: %w", err)
+	}
+
+	return os.WriteFile(name, append(content, '\n'), 0o600)
+}
+
+// AddSmartAlbum appends the definition, the album name must be unique and
+// the search valid, with at least one filter and a limit for smart queries.
+func AddSmartAlbum(albums []SmartAlbum, album SmartAlbum) ([]SmartAlbum, error) {
+	if album.Name == "" {
+		return albums, errors.New("smart album name is required")
+	}
+
+	if slices.ContainsFunc(albums, func(a SmartAlbum) bool { return a.Name == album.Name }) {
+		return albums, errors.Errorf("smart album %q already exists", album.Name)
+	}
+
+	if err := album.Search.validate(); err != nil {
+		return albums, errors.Errorf("smart album %q: %w", album.Name, err)
+	}
+
+	if !album.Search.filtered() {
+		return albums, errors.Errorf("smart album %q: at least one search filter is required", album.Name)
+	}
+
+	if album.Search.Query != "" && album.Search.Limit == 0 {
+		return albums, errors.Errorf("smart album %q: a limit is required with a smart search query", album.Name)
+	}
+
+	return append(albums, album), nil
+}
+
+// DeleteSmartAlbum removes the definition of the album name.
+func DeleteSmartAlbum(albums []SmartAlbum, name string) ([]SmartAlbum, error) {
+	i := slices.IndexFunc(albums, func(a SmartAlbum) bool { return a.Name == name })
+	if i < 0 {
+		return albums, errors.Errorf("smart album %q not found", name)
+	}
+
+	return slices.Delete(albums, i, i+1), nil
+}
+
+// SelectSmartAlbums returns the definitions named, or all the definitions
+// when no names are given.
+func SelectSmartAlbums(albums []SmartAlbum, names []string) ([]SmartAlbum, error) {
+	if len(names) == 0 {
+		return albums, nil
+	}
+
+	selected := make([]SmartAlbum, 0, len(names))
+
+	for _, name := range names {
+		i := slices.IndexFunc(albums, func(a SmartAlbum) bool { return a.Name == name })
+		if i < 0 {
+			return nil, errors.Errorf("smart album %q not found", name)
+		}
+
+		selected = append(selected, albums[i])
+	}
+
+	return selected, nil
+}
+
+// RefreshSmartAlbums runs the search of every definition sequentially,
+// creating the missing albums and adding the assets matched. When remove is
+// set, the album assets no longer matched are removed. A failing album does
+// not stop the next ones, the error returned joins the errors of all albums.
+func RefreshSmartAlbums(ctx context.Context, cl *client.Client, albums []SmartAlbum, remove bool) ([]SmartAlbumReport, error) {
+	reports := make([]SmartAlbumReport, 0, len(albums))
+
+	as, err := FetchAlbums(ctx, cl)
+	if err != nil {
+		return reports, err
+	}
+
+	var errs []error
+
+	for _, album := range albums {
+		if err := ctx.Err(); err != nil {
+			return reports, err
+		}
+
+		report := SmartAlbumReport{Album: album.Name}
+
+		a, err := refreshSmartAlbum(ctx, cl, album, as, remove, &report)
+		if err != nil {
+			errs = append(errs, errors.Errorf("smart album %q: %w", album.Name, err))
+		}
+
+		if report.Created {
+			as = append(as, a)
+		}
+
+		report.Err = err
+		reports = append(reports, report)
+	}
+
+	return reports, errors.Join(errs...)
+}
+
+// refreshSmartAlbum syncs the album assets with the search results.
+func refreshSmartAlbum(
+	ctx context.Context,
+	cl *client.Client,
+	album SmartAlbum,
+	as Albums,
+	remove bool,
+	report *SmartAlbumReport,
+) (Album, error) {
+	matches, err := SearchAssets(ctx, cl, &album.Search)
+	if err != nil {
+		return Album{}, err
+	}
+
+	ids := assetIDs(matches)
+	slices.Sort(ids)
+	ids = slices.Compact(ids)
+
+	var (
+		a       Album
+		current []string
+	)
+
+	if i := slices.IndexFunc(as, func(a Album) bool { return a.Name == album.Name }); i >= 0 {
+		a = as[i]
+
+		assets, err := fetchAlbumAssets(ctx, cl, a.ID)
+		if err != nil {
+			return a, err
+		}
+
+		current = assetIDs(assets)
+	} else {
+		if a, err = createAlbum(ctx, cl, album.Name, ""); err != nil {
+			return a, err
+		}
+
+		report.Created = true
+	}
+
+	members := make(map[string]bool, len(current))
+	for _, id := range current {
+		members[id] = true
+	}
+
+	added := slices.DeleteFunc(slices.Clone(ids), func(id string) bool {
+		return members[id]
+	})
+
+	if len(added) > 0 {
+		results, err := addAssetsToAlbum(ctx, cl, a.ID, added)
+		if err != nil {
+			return a, err
+		}
+
+		report.Added = results.added()
+
+		if err := results.failed().err(); err != nil {
+			return a, err
+		}
+	}
+
+	if !remove {
+		return a, nil
+	}
+
+	removed := slices.DeleteFunc(current, func(id string) bool {
+		_, found := slices.BinarySearch(ids, id)
+		return found
+	})
+
+	if len(removed) > 0 {
+		if err := removeAssetsFromAlbum(ctx, cl, a.ID, removed); err != nil {
+			return a, err
+		}
+
+		report.Removed = len(removed)
+	}
+
+	return a, nil
+}
@@ -0,0 +1,265 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+
+	"github.com/faabiosr/imt/internal/client"
+)
+
+func TestSmart_SmartAlbums(t *testing.T) {
+	t.Run("missing file", func(t *testing.T) {
+		albums, err := LoadSmartAlbums(filepath.Join(t.TempDir(), "smart_albums.json"))
+		if err != nil {
+			t.Fatalf("expected nil, got %v", err)
+		}
+
+		if len(albums) != 0 {
+			t.Errorf("unexpected smart albums: %v (expected none)", albums)
+		}
+	})
+
+	t.Run("save and load", func(t *testing.T) {
+		name := filepath.Join(t.TempDir(), "imt", "smart_albums.json")
+
+		albums, err := AddSmartAlbum(nil, SmartAlbum{
+			Name:   "Lisbon 2023",
+			Search: AssetSearch{City: "Lisbon", TakenAfter: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)},
+		})
+		if err != nil {
+			t.Fatalf("expected nil, got %v", err)
+		}
+
+		if err := SaveSmartAlbums(name, albums); err != nil {
+			t.Fatalf("expected nil, got %v", err)
+		}
+
+		loaded, err := LoadSmartAlbums(name)
+		if err != nil {
+			t.Fatalf("expected nil, got %v", err)
+		}
+
+		if !reflect.DeepEqual(loaded, albums) {
+			t.Errorf("unexpected smart albums: %v (expected %v)", loaded, albums)
+		}
+	})
+
+	t.Run("invalid definitions", func(t *testing.T) {
+		albums := []SmartAlbum{{Name: "Lisbon"}}
+
+		invalid := []SmartAlbum{
+			{},
+			{Name: "Lisbon"},
+			{Name: "Audio", Search: AssetSearch{Type: "audio"}},
+			{Name: "All"},
+			{Name: "All", Search: AssetSearch{Limit: 100}},
+			{Name: "Beach", Search: AssetSearch{Query: "beach"}},
+		}
+
+		for _, a := range invalid {
+			if _, err := AddSmartAlbum(albums, a); err == nil {
+				t.Errorf("expected an error for %+v, got nil", a)
+			}
+		}
+
+		if _, err := AddSmartAlbum(albums, SmartAlbum{Name: "Beach", Search: AssetSearch{Query: "beach", Limit: 100}}); err != nil {
+			t.Errorf("expected nil, got %v", err)
+		}
+
+		if _, err := DeleteSmartAlbum(albums, "Porto"); err == nil {
+			t.Error("expected an error, got nil")
+		}
+
+		if _, err := SelectSmartAlbums(albums, []string{"Porto"}); err == nil {
+			t.Error("expected an error, got nil")
+		}
+	})
+}
+
+func TestSmart_RefreshSmartAlbums(t *testing.T) {
+	albums := []SmartAlbum{
+		{Name: "Lisbon", Search: AssetSearch{City: "Lisbon"}},
+		{Name: "Porto", Search: AssetSearch{City: "Porto"}},
+	}
+
+	t.Run("failure", func(t *testing.T) {
+		hc := http.DefaultClient
+
+		httpmock.ActivateNonDefault(hc)
+		defer httpmock.DeactivateAndReset()
+
+		httpmock.RegisterResponder(
+			http.MethodGet,
+			testHost+"/api/albums",
+			httpmock.NewStringResponder(http.StatusOK, `[]`),
+		)
+
+		httpmock.RegisterResponder(
+			http.MethodPost,
+			testHost+"/api/search/metadata",
+			httpmock.NewJsonResponderOrPanic(http.StatusBadRequest, json.RawMessage(`{"message": "invalid search"}`)),
+		)
+
+		baseURL, _ := url.Parse(testHost)
+		cl := client.NewWithHTTPClient(baseURL, testAPIKey, hc)
+
+		reports, err := RefreshSmartAlbums(context.Background(), cl, albums, false)
+		if err == nil {
+			t.Fatal("expected an error, got nil")
+		}
+
+		if len(reports) != 2 || reports[0].Err == nil || reports[1].Err == nil {
+			t.Errorf("unexpected reports: %+v", reports)
+		}
+	})
+
+	t.Run("adds and removes assets", func(t *testing.T) {
+		hc := http.DefaultClient
+
+		httpmock.ActivateNonDefault(hc)
+		defer httpmock.DeactivateAndReset()
+
+		httpmock.RegisterResponder(
+			http.MethodGet,
+			testHost+"/api/albums",
+			httpmock.NewStringResponder(http.StatusOK, `[{"id": "a1", "albumName": "Lisbon"}]`),
+		)
+
+		httpmock.RegisterResponder(
+			http.MethodGet,
+			testHost+"/api/albums/a1",
+			httpmock.NewStringResponder(http.StatusOK, `{"id": "a1", "assets": [{"id": "1"}, {"id": "9"}]}`),
+		)
+
+		httpmock.RegisterResponder(
+			http.MethodPost,
+			testHost+"/api/albums",
+			httpmock.NewStringResponder(http.StatusCreated, `{"id": "a2", "albumName": "Porto"}`),
+		)
+
+		httpmock.RegisterResponder(
+			http.MethodPost,
+			testHost+"/api/search/metadata",
+			func(req *http.Request) (*http.Response, error) {
+				var body map[string]any
+				if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+					return nil, err
+				}
+
+				if body["city"] == "Lisbon" {
+					return httpmock.NewStringResponse(http.StatusOK, `{"assets": {"items": [{"id": "1"}, {"id": "2"}]}}`), nil
+				}
+
+				return httpmock.NewStringResponse(http.StatusOK, `{"assets": {"items": [{"id": "3"}]}}`), nil
+			},
+		)
+
+		changes := map[string][]any{}
+
+		record := func(req *http.Request) (*http.Response, error) {
+			var body map[string][]any
+			if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+				return nil, err
+			}
+
+			changes[req.Method+" "+req.URL.Path] = body["ids"]
+
+			results := []map[string]any{}
+			for _, id := range body["ids"] {
+				results = append(results, map[string]any{"id": id, "success": true})
+			}
+
+			return httpmock.NewJsonResponse(http.StatusOK, results)
+		}
+
+		httpmock.RegisterResponder(http.MethodPut, `=~^`+testHost+`/api/albums/(\w+)/assets`, record)
+		httpmock.RegisterResponder(http.MethodDelete, `=~^`+testHost+`/api/albums/(\w+)/assets`, record)
+
+		baseURL, _ := url.Parse(testHost)
+		cl := client.NewWithHTTPClient(baseURL, testAPIKey, hc)
+
+		reports, err := RefreshSmartAlbums(context.Background(), cl, albums, true)
+		if err != nil {
+			t.Fatalf("expected nil, got %v", err)
+		}
+
+		expected := []SmartAlbumReport{
+			{Album: "Lisbon", Added: 1, Removed: 1},
+			{Album: "Porto", Created: true, Added: 1},
+		}
+
+		if !reflect.DeepEqual(reports, expected) {
+			t.Errorf("unexpected reports: %+v (expected %+v)", reports, expected)
+		}
+
+		expectedChanges := map[string][]any{
+			"PUT /api/albums/a1/assets":    {"2"},
+			"DELETE /api/albums/a1/assets": {"9"},
+			"PUT /api/albums/a2/assets":    {"3"},
+		}
+
+		if !reflect.DeepEqual(changes, expectedChanges) {
+			t.Errorf("unexpected album changes: %v (expected %v)", changes, expectedChanges)
+		}
+	})
+
+	t.Run("partly failed assets", func(t *testing.T) {
+		hc := http.DefaultClient
+
+		httpmock.ActivateNonDefault(hc)
+		defer httpmock.DeactivateAndReset()
+
+		httpmock.RegisterResponder(
+			http.MethodGet,
+			testHost+"/api/albums",
+			httpmock.NewStringResponder(http.StatusOK, `[{"id": "a1", "albumName": "Lisbon"}]`),
+		)
+
+		httpmock.RegisterResponder(
+			http.MethodGet,
+			testHost+"/api/albums/a1",
+			httpmock.NewStringResponder(http.StatusOK, `{"id": "a1", "assets": [{"id": "9"}]}`),
+		)
+
+		httpmock.RegisterResponder(
+			http.MethodPost,
+			testHost+"/api/search/metadata",
+			httpmock.NewStringResponder(http.StatusOK, `{"assets": {"items": [{"id": "1"}, {"id": "2"}, {"id": "9"}]}}`),
+		)
+
+		httpmock.RegisterResponder(
+			http.MethodPut,
+			testHost+"/api/albums/a1/assets",
+			httpmock.NewStringResponder(
+				http.StatusOK,
+				`[{"id": "1", "success": true}, {"id": "2", "success": false, "error": "no_permission"}]`,
+			),
+		)
+
+		baseURL, _ := url.Parse(testHost)
+		cl := client.NewWithHTTPClient(baseURL, testAPIKey, hc)
+
+		reports, err := RefreshSmartAlbums(context.Background(), cl, albums[:1], false)
+		if err == nil || !strings.Contains(err.Error(), `asset "2": no_permission`) {
+			t.Errorf("unexpected error: %v (expected asset \"2\")", err)
+		}
+
+		if len(reports) != 1 || reports[0].Added != 1 || reports[0].Err == nil {
+			t.Errorf("unexpected reports: %+v", reports)
+		}
+	})
+}
@@ -0,0 +1,191 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/faabiosr/imt/internal/client"
+	"github.com/faabiosr/imt/internal/errors"
+)
+
+// Split criteria.
+const (
+	SplitByYear   = "year"
+	SplitByMonth  = "month"
+	SplitByFolder = "folder"
+)
+
+// SplitAlbumReport holds the outcome of an album split.
+type SplitAlbumReport struct {
+	Albums  []ChildAlbum
+	Skipped int
+}
+
+// ChildAlbum represents a child album created by a split.
+type ChildAlbum struct {
+	Name    string
+	Created bool
+	Assets  int
+}
+
+// SplitAlbum creates child albums from the assets of an album, referenced by
+// name or ID, grouped by the year or month the assets were taken or by their
+// folder. Child albums are named "<album> - <group>", existing ones are
+// reused, and the album is kept. Assets without a date are skipped when
+// splitting by date, and folders are named by their path relative to the
+// deepest folder shared by the assets.
+func SplitAlbum(ctx context.Context, cl *client.Client, ref, by string) (*SplitAlbumReport, error) {
+	report := &SplitAlbumReport{}
+
+	key, err := splitKey(by)
+	if err != nil {
+		return report, err
+	}
+
+	as, err := FetchAlbums(ctx, cl)
+	if err != nil {
+		return report, err
+	}
+
+	a, err := as.find(ref)
+	if err != nil {
+		return report, err
+	}
+
+	album, err := fetchAlbum(ctx, cl, a.ID)
+	if err != nil {
+		return report, err
+	}
+
+	groups := make(map[string][]string)
+
+	for _, asset := range album.Assets {
+		k := key(asset)
+		if k == "" {
+			report.Skipped++
+			continue
+		}
+
+		groups[k] = append(groups[k], asset.ID)
+	}
+
+	if by == SplitByFolder {
+		groups = relativeFolders(groups)
+	}
+
+	keys := make([]string, 0, len(groups))
+	for k := range groups {
+		keys = append(keys, k)
+	}
+
+	slices.Sort(keys)
+
+	failed := bulkIDResults{}
+
+	for _, k := range keys {
+		child := ChildAlbum{Name: fmt.Sprintf("%s - %s", album.Name, k)}
+
+		i := slices.IndexFunc(as, func(a Album) bool { return a.Name == child.Name })
+		if i < 0 {
+			created, err := createAlbum(ctx, cl, child.Name, "")
+			if err != nil {
+				return report, err
+			}
+
+			as = append(as, created)
+			i = len(as) - 1
+			child.Created = true
+		}
+
+		results, err := addAssetsToAlbum(ctx, cl, as[i].ID, groups[k])
+		if err != nil {
+			return report, err
+		}
+
+		child.Assets = results.added()
+		failed = append(failed, results.failed()...)
+
+		report.Albums = append(report.Albums, child)
+	}
+
+	return report, failed.err()
+}
+
+// splitKey returns the function grouping the assets by the criteria.
+func splitKey(by string) (func(a Asset) string, error) {
+	date := func(layout string) func(a Asset) string {
+		return func(a Asset) string {
+			if a.LocalDateTime.IsZero() {
+				return ""
+			}
+
+			return a.LocalDateTime.Format(layout)
+		}
+	}
+
+	switch by {
+	case SplitByYear:
+		return date("2006"), nil
+	case SplitByMonth:
+		return date("2006-01"), nil
+	case SplitByFolder:
+		return func(a Asset) string {
+			return filepath.Dir(a.OriginalPath)
+		}, nil
+	default:
+		return nil, errors.Errorf("invalid split criteria %q, must be year, month or folder", by)
+	}
+}
+
+// relativeFolders indexes the groups by their folder relative to the deepest
+// folder shared by all of them, or by its parent when the shared folder is a
+// group itself, so that the folders with the same name are kept apart.
+func relativeFolders(groups map[string][]string) map[string][]string {
+	var root []string
+
+	for folder := range groups {
+		segments := strings.Split(folder, string(os.PathSeparator))
+		if root == nil {
+			root = segments
+			continue
+		}
+
+		n := 0
+		for n < len(root) && n < len(segments) && root[n] == segments[n] {
+			n++
+		}
+
+		root = root[:n]
+	}
+
+	base := strings.Join(root, string(os.PathSeparator))
+	if base == "" {
+		base = string(os.PathSeparator)
+	}
+
+	if _, ok := groups[base]; ok {
+		base = filepath.Dir(base)
+	}
+
+	rel := make(map[string][]string, len(groups))
+
+	for folder, ids := range groups {
+		k, err := filepath.Rel(base, folder)
+		if err != nil || k == "." {
+			k = filepath.Base(folder)
+		}
+
+		rel[filepath.ToSlash(k)] = ids
+	}
+
+	return rel
+}
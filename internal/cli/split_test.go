@@ -0,0 +1,224 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"net/http"
+	"net/url"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+
+	"github.com/faabiosr/imt/internal/client"
+)
+
+func TestSplit_SplitAlbum(t *testing.T) {
+	t.Run("invalid criteria", func(t *testing.T) {
+		if _, err := SplitAlbum(context.Background(), nil, "Trips", "week"); err == nil {
+			t.Error("expected an error, got nil")
+		}
+	})
+
+	tests := []struct {
+		by       string
+		expected *SplitAlbumReport
+		assets   map[string][]any
+		err      string
+	}{
+		{
+			by: SplitByYear,
+			expected: &SplitAlbumReport{
+				Albums: []ChildAlbum{
+					{Name: "Trips - 2023", Assets: 2},
+					{Name: "Trips - 2024", Created: true, Assets: 1},
+				},
+				Skipped: 1,
+			},
+			assets: map[string][]any{
+				"/api/albums/a2/assets":  {"1", "2"},
+				"/api/albums/new/assets": {"3"},
+			},
+		},
+		{
+			by: SplitByMonth,
+			expected: &SplitAlbumReport{
+				Albums: []ChildAlbum{
+					{Name: "Trips - 2023-07", Created: true, Assets: 1},
+					{Name: "Trips - 2023-08", Created: true, Assets: 1},
+					{Name: "Trips - 2024-01", Created: true, Assets: 1},
+				},
+				Skipped: 1,
+			},
+			assets: map[string][]any{
+				"/api/albums/new/assets": {"3"},
+			},
+		},
+		{
+			by: SplitByFolder,
+			expected: &SplitAlbumReport{
+				Albums: []ChildAlbum{
+					{Name: "Trips - Lisbon", Created: true, Assets: 2},
+					{Name: "Trips - Porto", Created: true, Assets: 2},
+				},
+			},
+			assets: map[string][]any{
+				"/api/albums/new/assets": {"2", "4"},
+			},
+		},
+		{
+			by: SplitByYear,
+			expected: &SplitAlbumReport{
+				Albums: []ChildAlbum{
+					{Name: "Trips - 2023", Assets: 1},
+					{Name: "Trips - 2024", Created: true, Assets: 1},
+				},
+				Skipped: 1,
+			},
+			assets: map[string][]any{
+				"/api/albums/a2/assets":  {"1", "2"},
+				"/api/albums/new/assets": {"3"},
+			},
+			err: `asset "2": no_permission`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.by, func(t *testing.T) {
+			hc := http.DefaultClient
+
+			httpmock.ActivateNonDefault(hc)
+			defer httpmock.DeactivateAndReset()
+
+			httpmock.RegisterResponder(
+				http.MethodGet,
+				testHost+"/api/albums",
+				httpmock.NewStringResponder(http.StatusOK, `[{"id": "a1", "albumName": "Trips"}, {"id": "a2", "albumName": "Trips - 2023"}]`),
+			)
+
+			httpmock.RegisterResponder(
+				http.MethodGet,
+				testHost+"/api/albums/a1",
+				httpmock.NewStringResponder(http.StatusOK, `{"id": "a1", "albumName": "Trips", "assets": [
+					{"id": "1", "originalPath": "/photos/Lisbon/a.jpg", "localDateTime": "2023-07-01T10:00:00.000Z"},
+					{"id": "2", "originalPath": "/photos/Porto/b.jpg", "localDateTime": "2023-08-01T10:00:00.000Z"},
+					{"id": "3", "originalPath": "/photos/Lisbon/c.jpg", "localDateTime": "2024-01-01T10:00:00.000Z"},
+					{"id": "4", "originalPath": "/photos/Porto/d.jpg"}
+				]}`),
+			)
+
+			httpmock.RegisterResponder(
+				http.MethodPost,
+				testHost+"/api/albums",
+				httpmock.NewStringResponder(http.StatusCreated, `{"id": "new"}`),
+			)
+
+			assets := map[string][]any{}
+
+			httpmock.RegisterResponder(
+				http.MethodPut,
+				`=~^`+testHost+`/api/albums/(\w+)/assets`,
+				func(req *http.Request) (*http.Response, error) {
+					var body map[string][]any
+					if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+						return nil, err
+					}
+
+					// the last request of every album is kept.
+					assets[req.URL.Path] = body["ids"]
+
+					results := []bulkIDResult{}
+					for _, id := range body["ids"] {
+						if tt.err != "" && id == "2" {
+							results = append(results, bulkIDResult{ID: "2", Error: "no_permission"})
+							continue
+						}
+
+						results = append(results, bulkIDResult{ID: id.(string), Success: true})
+					}
+
+					return httpmock.NewJsonResponse(http.StatusOK, results)
+				},
+			)
+
+			baseURL, _ := url.Parse(testHost)
+			cl := client.NewWithHTTPClient(baseURL, testAPIKey, hc)
+
+			report, err := SplitAlbum(context.Background(), cl, "Trips", tt.by)
+			if tt.err == "" && err != nil {
+				t.Fatalf("expected nil, got %v", err)
+			}
+
+			if tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)) {
+				t.Errorf("unexpected error: %v (expected %s)", err, tt.err)
+			}
+
+			if !reflect.DeepEqual(report, tt.expected) {
+				t.Errorf("unexpected report: %+v (expected %+v)", report, tt.expected)
+			}
+
+			if !reflect.DeepEqual(assets, tt.assets) {
+				t.Errorf("unexpected assets added: %v (expected %v)", assets, tt.assets)
+			}
+		})
+	}
+}
+
+func TestSplit_relativeFolders(t *testing.T) {
+	tests := []struct {
+		name     string
+		folders  []string
+		expected []string
+	}{
+		{
+			name:     "folders sharing a name",
+			folders:  []string{"/2023/Beach", "/2024/Beach"},
+			expected: []string{"2023/Beach", "2024/Beach"},
+		},
+		{
+			name:     "shared parent",
+			folders:  []string{"/photos/2023/Beach", "/photos/2024", "/photos/2024/Beach"},
+			expected: []string{"2023/Beach", "2024", "2024/Beach"},
+		},
+		{
+			name:     "shared folder is a group",
+			folders:  []string{"/photos/Lisbon", "/photos/Lisbon/Best"},
+			expected: []string{"Lisbon", "Lisbon/Best"},
+		},
+		{
+			name:     "single folder",
+			folders:  []string{"/photos/Lisbon"},
+			expected: []string{"Lisbon"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			groups := make(map[string][]string, len(tt.folders))
+			for _, folder := range tt.folders {
+				groups[folder] = []string{folder}
+			}
+
+			rel := relativeFolders(groups)
+
+			keys := slices.Sorted(maps.Keys(rel))
+			if !reflect.DeepEqual(keys, tt.expected) {
+				t.Errorf("unexpected folders: %v (expected %v)", keys, tt.expected)
+			}
+
+			for i, k := range tt.expected {
+				if ids := rel[k]; len(ids) != 1 || ids[0] != tt.folders[i] {
+					t.Errorf("unexpected assets of %s: %v (expected [%s])", k, ids, tt.folders[i])
+				}
+			}
+		})
+	}
+}
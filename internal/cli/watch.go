@@ -0,0 +1,422 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package cli
+
+import (
+	"cmp"
+	"context"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/faabiosr/imt/internal/client"
+	"github.com/faabiosr/imt/internal/errors"
+)
+
+// Watch mode defaults.
+const (
+	DefaultWatchDebounce     = 5 * time.Second
+	DefaultWatchPollInterval = 30 * time.Second
+)
+
+// WatchOptions handles the options of the watch mode.
+type WatchOptions struct {
+	// Debounce is the quiet time waited after a change before syncing.
+	Debounce time.Duration
+	// Poll forces polling the folders instead of using file system events.
+	Poll bool
+	// PollInterval is the polling interval, also used to retry failed syncs.
+	PollInterval time.Duration
+}
+
+// WatchAutoCreateAlbums auto creates the albums and keeps them in sync as the
+// local folder tree changes, until the context is done. After the first pass,
+// only the folders added or changed are synced. The outcome of every pass
+// syncing folders is sent to fn, failed passes are retried.
+func WatchAutoCreateAlbums(
+	ctx context.Context,
+	cl *client.Client,
+	opts *AutoCreateAlbumsOptions,
+	wopts WatchOptions,
+	fn func(*AutoCreateAlbumsReport, error),
+) error {
+	if opts.Remote {
+		return errors.New("watch is not supported with remote folders")
+	}
+
+	if err := opts.Media.validate(); err != nil {
+		return err
+	}
+
+	if _, err := newAlbumNamer(opts); err != nil {
+		return err
+	}
+
+	wopts.Debounce = cmp.Or(wopts.Debounce, DefaultWatchDebounce)
+	wopts.PollInterval = cmp.Or(wopts.PollInterval, DefaultWatchPollInterval)
+
+	w := &folderWatcher{
+		cl:       cl,
+		opts:     opts,
+		wopts:    wopts,
+		notifier: newFolderNotifier(wopts),
+		paths:    map[string]bool{},
+	}
+
+	defer func() {
+		_ = w.notifier.close()
+	}()
+
+	report, err := w.sync(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	fn(report, nil)
+
+	return w.run(ctx, fn)
+}
+
+// folderWatcher syncs the albums of the folders changed.
+type folderWatcher struct {
+	cl       *client.Client
+	opts     *AutoCreateAlbumsOptions
+	wopts    WatchOptions
+	notifier folderNotifier
+	paths    map[string]bool
+}
+
+// run collects the changes notified, syncing them once no change happens
+// during the debounce time.
+func (w *folderWatcher) run(ctx context.Context, fn func(*AutoCreateAlbumsReport, error)) error {
+	pending := map[string]bool{}
+	full := false
+
+	timer := time.NewTimer(w.wopts.Debounce)
+	timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case path := <-w.notifier.changes():
+			if path == "" {
+				full = true
+			} else {
+				pending[path] = true
+				pending[filepath.Dir(path)] = true
+			}
+
+			timer.Reset(w.wopts.Debounce)
+		case <-timer.C:
+			changed := pending
+			if full {
+				changed = nil
+			}
+
+			report, err := w.sync(ctx, changed)
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			if err != nil {
+				timer.Reset(w.wopts.PollInterval)
+			} else {
+				pending = map[string]bool{}
+				full = false
+			}
+
+			if len(report.Folders) > 0 || err != nil {
+				fn(report, err)
+			}
+		}
+	}
+}
+
+// sync lists the folder tree and syncs the folders added since the last pass
+// and the ones of the local paths changed. Every folder is synced when
+// changed is nil.
+func (w *folderWatcher) sync(ctx context.Context, changed map[string]bool) (*AutoCreateAlbumsReport, error) {
+	report := &AutoCreateAlbumsReport{}
+
+	namer, err := newAlbumNamer(w.opts)
+	if err != nil {
+		return report, err
+	}
+
+	tree, err := listFolderTree(ctx, w.cl, w.opts)
+	if err != nil {
+		return report, err
+	}
+
+	// folders are watched before syncing, so changes made meanwhile are
+	// not missed.
+	if err := w.watch(slices.Collect(maps.Keys(tree.dirs))); err != nil {
+		return report, err
+	}
+
+	paths := map[string]bool{}
+	folders := map[string]bool{}
+
+	for _, p := range tree.paths {
+		paths[p] = true
+		folders[p] = !w.paths[p]
+	}
+
+	for dir := range changed {
+		if p, ok := tree.dirs[dir]; ok && paths[p] {
+			folders[p] = true
+		}
+	}
+
+	var filter func(string) bool
+	if changed != nil {
+		filter = func(p string) bool { return folders[p] }
+	}
+
+	if err := syncAlbums(ctx, w.cl, w.opts, namer, tree.paths, filter, report); err != nil {
+		return report, err
+	}
+
+	w.paths = paths
+
+	return report, nil
+}
+
+// watch replaces the folders watched, falling back to polling when the file
+// system events fail, e.g. when the limit of inotify watches is reached.
+func (w *folderWatcher) watch(dirs []string) error {
+	err := w.notifier.watch(dirs)
+	if _, ok := w.notifier.(*folderPoller); ok || err == nil {
+		return err
+	}
+
+	_ = w.notifier.close()
+	w.notifier = newFolderPoller(w.wopts.PollInterval)
+
+	return w.notifier.watch(dirs)
+}
+
+// folderNotifier notifies the changes of local folders.
+type folderNotifier interface {
+	// watch replaces the folders watched.
+	watch(dirs []string) error
+
+	// changes sends the paths changed, an empty path means changes were
+	// lost and every folder must be synced.
+	changes() <-chan string
+
+	close() error
+}
+
+// newFolderNotifier creates a notifier using file system events, or polling
+// when forced or when file system events are not available.
+func newFolderNotifier(wopts WatchOptions) folderNotifier {
+	if !wopts.Poll {
+		if n, err := newEventNotifier(); err == nil {
+			return n
+		}
+	}
+
+	return newFolderPoller(wopts.PollInterval)
+}
+
+// eventNotifier notifies the changes from file system events (inotify,
+// kqueue or ReadDirectoryChangesW), which are not recursive.
+type eventNotifier struct {
+	w    *fsnotify.Watcher
+	dirs map[string]bool
+	out  chan string
+	done chan struct{}
+}
+
+func newEventNotifier() (*eventNotifier, error) {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	n := &eventNotifier{
+		w:    w,
+		dirs: map[string]bool{},
+		out:  make(chan string),
+		done: make(chan struct{}),
+	}
+
+	go n.loop()
+
+	return n, nil
+}
+
+func (n *eventNotifier) loop() {
+	for {
+		select {
+		case e, ok := <-n.w.Events:
+			if !ok {
+				return
+			}
+
+			if e.Op != fsnotify.Chmod {
+				n.send(e.Name)
+			}
+		case _, ok := <-n.w.Errors:
+			if !ok {
+				return
+			}
+
+			n.send("")
+		}
+	}
+}
+
+func (n *eventNotifier) send(path string) {
+	select {
+	case n.out <- path:
+	case <-n.done:
+	}
+}
+
+func (n *eventNotifier) watch(dirs []string) error {
+	next := make(map[string]bool, len(dirs))
+
+	for _, dir := range dirs {
+		next[dir] = true
+
+		if n.dirs[dir] {
+			continue
+		}
+
+		// folders removed meanwhile are notified by their parent.
+		if err := n.w.Add(dir); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return errors.Errorf("unable to watch folder %s: %w", dir, err)
+		}
+	}
+
+	for dir := range n.dirs {
+		if !next[dir] {
+			_ = n.w.Remove(dir)
+		}
+	}
+
+	n.dirs = next
+
+	return nil
+}
+
+func (n *eventNotifier) changes() <-chan string {
+	return n.out
+}
+
+func (n *eventNotifier) close() error {
+	close(n.done)
+	return n.w.Close()
+}
+
+// folderPoller notifies the folders whose modification time changed, which
+// happens when files are added, removed or renamed.
+type folderPoller struct {
+	mu   sync.Mutex
+	dirs map[string]time.Time
+	out  chan string
+	done chan struct{}
+}
+
+func newFolderPoller(interval time.Duration) *folderPoller {
+	p := &folderPoller{
+		dirs: map[string]time.Time{},
+		out:  make(chan string),
+		done: make(chan struct{}),
+	}
+
+	go p.loop(interval)
+
+	return p
+}
+
+func (p *folderPoller) loop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.done:
+			return
+		case <-ticker.C:
+			for _, dir := range p.poll() {
+				select {
+				case p.out <- dir:
+				case <-p.done:
+					return
+				}
+			}
+		}
+	}
+}
+
+// poll returns the folders changed since the last poll.
+func (p *folderPoller) poll() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var changed []string
+
+	for dir, last := range p.dirs {
+		if t := modTime(dir); !t.Equal(last) {
+			p.dirs[dir] = t
+			changed = append(changed, dir)
+		}
+	}
+
+	slices.Sort(changed)
+
+	return changed
+}
+
+func (p *folderPoller) watch(dirs []string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	next := make(map[string]time.Time, len(dirs))
+
+	for _, dir := range dirs {
+		if t, ok := p.dirs[dir]; ok {
+			next[dir] = t
+			continue
+		}
+
+		next[dir] = modTime(dir)
+	}
+
+	p.dirs = next
+
+	return nil
+}
+
+func (p *folderPoller) changes() <-chan string {
+	return p.out
+}
+
+func (p *folderPoller) close() error {
+	close(p.done)
+	return nil
+}
+
+// modTime returns the modification time of the path, or the zero time when
+// the path does not exist anymore.
+func modTime(path string) time.Time {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+
+	return info.ModTime()
+}
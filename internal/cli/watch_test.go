@@ -0,0 +1,239 @@
+/*
+ * Copyright (c) Fabio da Silva Ribeiro <faabiosr@gmail.com>
+ * SPDX-License-Identifier: MIT
+ */
+
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+
+	"github.com/faabiosr/imt/internal/client"
+)
+
+func TestWatch_notifiers(t *testing.T) {
+	events, err := newEventNotifier()
+	if err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+
+	notifiers := map[string]folderNotifier{
+		"events":  events,
+		"polling": newFolderPoller(10 * time.Millisecond),
+	}
+
+	for name, n := range notifiers {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				_ = n.close()
+			}()
+
+			tmp := t.TempDir()
+
+			if err := n.watch([]string{tmp}); err != nil {
+				t.Fatalf("expected nil, got %v", err)
+			}
+
+			// makes sure the folder modification time changes.
+			time.Sleep(20 * time.Millisecond)
+
+			if err := os.WriteFile(filepath.Join(tmp, "a.jpg"), []byte("testing"), 0o600); err != nil {
+				t.Fatalf("expected nil, got %v", err)
+			}
+
+			select {
+			case path := <-n.changes():
+				if path != tmp && filepath.Dir(path) != tmp {
+					t.Errorf("unexpected path changed: %s (expected %s)", path, tmp)
+				}
+			case <-time.After(5 * time.Second):
+				t.Error("expected a change, got none")
+			}
+		})
+	}
+}
+
+func TestWatch_sync(t *testing.T) {
+	ctx := context.Background()
+	hc := http.DefaultClient
+
+	httpmock.ActivateNonDefault(hc)
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder(
+		http.MethodGet,
+		testHost+"/api/albums",
+		httpmock.NewJsonResponderOrPanic(http.StatusOK, json.RawMessage(`[]`)),
+	)
+
+	httpmock.RegisterResponder(
+		http.MethodGet,
+		testHost+"/api/view/folder",
+		func(req *http.Request) (*http.Response, error) {
+			return httpmock.NewJsonResponse(http.StatusOK, []Asset{{ID: req.URL.Query().Get("path")}})
+		},
+	)
+
+	httpmock.RegisterResponder(
+		http.MethodPost,
+		testHost+"/api/albums",
+		func(req *http.Request) (*http.Response, error) {
+			var body map[string]string
+			if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+				return nil, err
+			}
+
+			return httpmock.NewJsonResponse(http.StatusOK, Album{ID: body["albumName"], Name: body["albumName"]})
+		},
+	)
+
+	var (
+		mu    sync.Mutex
+		added []string
+	)
+
+	httpmock.RegisterRegexpResponder(
+		http.MethodPut,
+		regexp.MustCompile(`/api/albums/.+/assets$`),
+		func(req *http.Request) (*http.Response, error) {
+			var body struct {
+				IDs []string `json:"ids"`
+			}
+
+			if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+				return nil, err
+			}
+
+			mu.Lock()
+			added = append(added, body.IDs...)
+			mu.Unlock()
+
+			return httpmock.NewStringResponse(http.StatusOK, `[]`), nil
+		},
+	)
+
+	tmp := t.TempDir()
+
+	for _, dir := range []string{"food", "trip"} {
+		if err := os.Mkdir(filepath.Join(tmp, dir), 0o755); err != nil {
+			t.Fatalf("expected nil, got %v", err)
+		}
+	}
+
+	baseURL, _ := url.Parse(testHost)
+
+	w := &folderWatcher{
+		cl:       client.NewWithHTTPClient(baseURL, testAPIKey, hc),
+		opts:     &AutoCreateAlbumsOptions{Folder: tmp + string(os.PathSeparator)},
+		wopts:    WatchOptions{PollInterval: time.Hour},
+		notifier: newFolderPoller(time.Hour),
+		paths:    map[string]bool{},
+	}
+
+	defer func() {
+		_ = w.notifier.close()
+	}()
+
+	tests := []struct {
+		name    string
+		prepare func() error
+		changed map[string]bool
+		want    []string
+	}{
+		{
+			name: "first pass",
+			want: []string{"/food", "/trip"},
+		},
+		{
+			name:    "nothing changed",
+			changed: map[string]bool{},
+		},
+		{
+			name: "folder added",
+			prepare: func() error {
+				return os.Mkdir(filepath.Join(tmp, "beach"), 0o755)
+			},
+			changed: map[string]bool{tmp: true},
+			want:    []string{"/beach"},
+		},
+		{
+			name:    "folder changed",
+			changed: map[string]bool{filepath.Join(tmp, "trip"): true, filepath.Join(tmp, "trip", "a.jpg"): true},
+			want:    []string{"/trip"},
+		},
+		{
+			name:    "changes lost",
+			changed: nil,
+			want:    []string{"/beach", "/food", "/trip"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.prepare != nil {
+				if err := tt.prepare(); err != nil {
+					t.Fatalf("expected nil, got %v", err)
+				}
+			}
+
+			added = nil
+
+			report, err := w.sync(ctx, tt.changed)
+			if err != nil {
+				t.Errorf("expected nil, got %v", err)
+			}
+
+			if !reflect.DeepEqual(report.Folders, tt.want) {
+				t.Errorf("unexpected folders: %v (expected %v)", report.Folders, tt.want)
+			}
+
+			if len(added) != len(tt.want) {
+				t.Errorf("unexpected assets added: %v (expected %v)", added, tt.want)
+			}
+		})
+	}
+}
+
+func TestWatch_WatchAutoCreateAlbums(t *testing.T) {
+	t.Run("remote folder", func(t *testing.T) {
+		opts := &AutoCreateAlbumsOptions{Folder: "/external/2025/", Remote: true}
+
+		err := WatchAutoCreateAlbums(context.Background(), nil, opts, WatchOptions{}, nil)
+		if err == nil || !strings.Contains(err.Error(), "remote") {
+			t.Errorf("unexpected error: %v (expected remote folders error)", err)
+		}
+	})
+
+	t.Run("stops when the context is done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+
+		opts := &AutoCreateAlbumsOptions{Folder: t.TempDir() + string(os.PathSeparator)}
+
+		passes := 0
+		fn := func(_ *AutoCreateAlbumsReport, _ error) {
+			passes++
+			cancel()
+		}
+
+		if err := WatchAutoCreateAlbums(ctx, nil, opts, WatchOptions{Poll: true}, fn); err != nil {
+			t.Errorf("expected nil, got %v", err)
+		}
+
+		if passes != 1 {
+			t.Errorf("unexpected number of passes: %d (expected 1)", passes)
+		}
+	})
+}